@@ -0,0 +1,175 @@
+// Package anim describes spritesheets as named clips of frames and plays
+// them back. Sheets come from a sidecar metadata file next to the image,
+// either this package's own format or an Aseprite export, or from a plain
+// row of equal frames when there is none.
+package anim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Frame is one image of a clip.
+type Frame struct {
+	Rect     pixel.Rect // area of the sheet's picture, in picture coordinates
+	Duration float64    // seconds on screen; 0 means the player's default
+
+	// Offset moves the frame from the sprite's position, for frames trimmed
+	// down from a larger canvas so they still line up with the others.
+	Offset pixel.Vec
+
+	// Slices are named areas of the frame, such as a hitbox, relative to
+	// the sprite's position.
+	Slices map[string]pixel.Rect
+}
+
+// Clip is a named sequence of frames, such as "walk" or "idle".
+type Clip struct {
+	Name   string
+	Frames []Frame
+	Loop   bool
+}
+
+// Sheet is a picture and the clips cut from it. The first clip is the one
+// played when a requested clip doesn't exist.
+type Sheet struct {
+	Picture *pixel.PictureData
+	Clips   []*Clip
+}
+
+// Clip returns the clip called name, or nil if the sheet has none.
+func (s *Sheet) Clip(name string) *Clip {
+	for _, c := range s.Clips {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Strip cuts pic into a row of size x size frames and plays them all as one
+// looping clip called name. It is how a sheet without metadata is read.
+func Strip(pic *pixel.PictureData, size float64, name string) (*Sheet, error) {
+	n := int(pic.Bounds().W() / size)
+	if n < 1 || pic.Bounds().H() < size {
+		return nil, fmt.Errorf("%vx%v is smaller than one %vx%v frame", pic.Bounds().W(), pic.Bounds().H(), size, size)
+	}
+	clip := &Clip{Name: name, Loop: true}
+	top := pic.Bounds().Max.Y
+	for i := 0; i < n; i++ {
+		x := pic.Bounds().Min.X + float64(i)*size
+		clip.Frames = append(clip.Frames, Frame{Rect: pixel.R(x, top-size, x+size, top)})
+	}
+	return &Sheet{Picture: pic, Clips: []*Clip{clip}}, nil
+}
+
+// metadata is the sidecar file format. Frame rectangles are in image
+// coordinates, measured from the top-left corner as in any paint program.
+//
+//	{
+//	  "frames": [{"x": 0, "y": 0, "w": 32, "h": 32}, ...],
+//	  "animations": [
+//	    {"name": "walk", "frames": [0, 1, 2, 3], "duration": 0.15, "loop": true},
+//	    {"name": "hop", "frames": [4, 5], "durations": [0.1, 0.3]}
+//	  ]
+//	}
+//
+// "duration" applies to every frame of an animation; "durations" sets each
+// frame's separately and wins where both are given.
+type metadata struct {
+	Frames []struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frames"`
+	Animations []struct {
+		Name      string    `json:"name"`
+		Frames    []int     `json:"frames"`
+		Duration  float64   `json:"duration"`
+		Durations []float64 `json:"durations"`
+		Loop      bool      `json:"loop"`
+	} `json:"animations"`
+}
+
+// Parse reads sidecar metadata describing the clips in pic. Aseprite
+// exports, recognised by their "meta" member, are handed to ParseAseprite.
+func Parse(data []byte, pic *pixel.PictureData) (*Sheet, error) {
+	var probe struct {
+		Meta json.RawMessage `json:"meta"`
+	}
+	if json.Unmarshal(data, &probe) == nil && probe.Meta != nil {
+		return ParseAseprite(data, pic)
+	}
+
+	var md metadata
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&md); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	b := pic.Bounds()
+	rects := make([]pixel.Rect, len(md.Frames))
+	for i, f := range md.Frames {
+		if f.W <= 0 || f.H <= 0 || f.X < 0 || f.Y < 0 || float64(f.X+f.W) > b.W() || float64(f.Y+f.H) > b.H() {
+			fail("frame %d (%d,%d %dx%d) is not inside the %vx%v image", i, f.X, f.Y, f.W, f.H, b.W(), b.H())
+			continue
+		}
+		// Flip from image rows, counted down, to picture coordinates.
+		x, y := b.Min.X+float64(f.X), b.Max.Y-float64(f.Y+f.H)
+		rects[i] = pixel.R(x, y, x+float64(f.W), y+float64(f.H))
+	}
+
+	sheet := &Sheet{Picture: pic}
+	for _, a := range md.Animations {
+		switch {
+		case a.Name == "":
+			fail("an animation has no name")
+			continue
+		case sheet.Clip(a.Name) != nil:
+			fail("animation %q is defined twice", a.Name)
+			continue
+		case len(a.Frames) == 0:
+			fail("animation %q has no frames", a.Name)
+			continue
+		case a.Durations != nil && len(a.Durations) != len(a.Frames):
+			fail("animation %q has %d frames but %d durations", a.Name, len(a.Frames), len(a.Durations))
+			continue
+		}
+		clip := &Clip{Name: a.Name, Loop: a.Loop}
+		for i, idx := range a.Frames {
+			if idx < 0 || idx >= len(rects) {
+				fail("animation %q uses frame %d, but there are %d frames", a.Name, idx, len(rects))
+				continue
+			}
+			d := a.Duration
+			if a.Durations != nil {
+				d = a.Durations[i]
+			}
+			if d < 0 {
+				fail("animation %q has a negative duration", a.Name)
+			}
+			clip.Frames = append(clip.Frames, Frame{Rect: rects[idx], Duration: d})
+		}
+		sheet.Clips = append(sheet.Clips, clip)
+	}
+	if len(md.Animations) == 0 {
+		fail("no animations")
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return sheet, nil
+}
@@ -0,0 +1,277 @@
+package anim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// row is a picture holding four 16x16 frames side by side.
+var row = pixel.MakePictureData(pixel.R(0, 0, 64, 16))
+
+// indices tells which frames of row a clip plays, by their position.
+func indices(c *Clip) []int {
+	var idx []int
+	for _, f := range c.Frames {
+		idx = append(idx, int(f.Rect.Min.X)/16)
+	}
+	return idx
+}
+
+func TestParse(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 32, 48))
+	sheet, err := Parse([]byte(`{
+		"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}, {"x": 16, "y": 32, "w": 16, "h": 16}],
+		"animations": [
+			{"name": "walk", "frames": [0, 1], "duration": 0.15, "loop": true},
+			{"name": "hop", "frames": [1, 0, 1], "duration": 0.2, "durations": [0.1, 0.3, 0.5]}
+		]
+	}`), pic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walk := sheet.Clip("walk")
+	if walk == nil || !walk.Loop || len(walk.Frames) != 2 {
+		t.Fatalf("walk = %+v", walk)
+	}
+	// Image rows count down from the top; picture coordinates count up.
+	if want := pixel.R(0, 32, 16, 48); walk.Frames[0].Rect != want {
+		t.Errorf("frame 0 at %v, want %v", walk.Frames[0].Rect, want)
+	}
+	if want := pixel.R(16, 0, 32, 16); walk.Frames[1].Rect != want {
+		t.Errorf("frame 1 at %v, want %v", walk.Frames[1].Rect, want)
+	}
+	if walk.Frames[0].Duration != 0.15 {
+		t.Errorf("walk duration %v, want 0.15", walk.Frames[0].Duration)
+	}
+
+	hop := sheet.Clip("hop")
+	var durations []float64
+	for _, f := range hop.Frames {
+		durations = append(durations, f.Duration)
+	}
+	if want := []float64{0.1, 0.3, 0.5}; !reflect.DeepEqual(durations, want) {
+		t.Errorf("hop durations %v, want %v", durations, want)
+	}
+	if hop.Loop {
+		t.Error("hop loops")
+	}
+	if sheet.Clip("run") != nil {
+		t.Error("found a clip that isn't there")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no animations", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}]}`, "no animations"},
+		{"frame outside", `{"frames": [{"x": 60, "y": 0, "w": 16, "h": 16}], "animations": [{"name": "a", "frames": [0]}]}`, "not inside the 64x16 image"},
+		{"missing frame", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}], "animations": [{"name": "a", "frames": [3]}]}`, "uses frame 3, but there are 1 frames"},
+		{"unnamed", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}], "animations": [{"frames": [0]}]}`, "no name"},
+		{"twice", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}], "animations": [{"name": "a", "frames": [0]}, {"name": "a", "frames": [0]}]}`, `"a" is defined twice`},
+		{"durations", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}], "animations": [{"name": "a", "frames": [0], "durations": [1, 2]}]}`, "1 frames but 2 durations"},
+		{"negative", `{"frames": [{"x": 0, "y": 0, "w": 16, "h": 16}], "animations": [{"name": "a", "frames": [0], "duration": -1}]}`, "negative duration"},
+		{"unknown field", `{"frames": [], "animations": [], "fps": 12}`, `unknown field "fps"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), row)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// aseprite returns an array-layout export of row's four frames with the
+// given tags and slices.
+func aseprite(tags, slices string) string {
+	var frames []string
+	for i := 0; i < 4; i++ {
+		frames = append(frames, fmt.Sprintf(`{
+			"filename": "f%d", "frame": {"x": %d, "y": 0, "w": 16, "h": 16},
+			"sourceSize": {"w": 16, "h": 16}, "duration": 100
+		}`, i, i*16))
+	}
+	return fmt.Sprintf(`{"frames": [%s], "meta": {
+		"app": "https://www.aseprite.org/", "size": {"w": 64, "h": 16},
+		"frameTags": [%s], "slices": [%s]
+	}}`, strings.Join(frames, ","), tags, slices)
+}
+
+func TestAsepriteTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want []int
+		loop bool
+	}{
+		{"forward", `{"name": "t", "from": 0, "to": 3, "direction": "forward"}`, []int{0, 1, 2, 3}, true},
+		{"no direction", `{"name": "t", "from": 1, "to": 2}`, []int{1, 2}, true},
+		{"reverse", `{"name": "t", "from": 0, "to": 2, "direction": "reverse"}`, []int{2, 1, 0}, true},
+		{"pingpong", `{"name": "t", "from": 0, "to": 3, "direction": "pingpong"}`, []int{0, 1, 2, 3, 2, 1}, true},
+		{"pingpong reverse", `{"name": "t", "from": 0, "to": 3, "direction": "pingpong_reverse"}`, []int{3, 2, 1, 0, 1, 2}, true},
+		{"short pingpong", `{"name": "t", "from": 1, "to": 2, "direction": "pingpong"}`, []int{1, 2}, true},
+		{"repeat once", `{"name": "t", "from": 0, "to": 1, "repeat": "1"}`, []int{0, 1}, false},
+		{"repeat thrice", `{"name": "t", "from": 2, "to": 3, "repeat": "3"}`, []int{2, 3, 2, 3, 2, 3}, false},
+		{"repeat forever", `{"name": "t", "from": 0, "to": 1, "repeat": "0"}`, []int{0, 1}, true},
+		{"repeated pingpong", `{"name": "t", "from": 0, "to": 2, "direction": "pingpong", "repeat": "2"}`, []int{0, 1, 2, 1, 0, 1, 2, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := Parse([]byte(aseprite(tt.tag, "")), row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := sheet.Clip("t")
+			if c == nil {
+				t.Fatal("no clip")
+			}
+			if got := indices(c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("plays frames %v, want %v", got, tt.want)
+			}
+			if c.Loop != tt.loop {
+				t.Errorf("loop = %v, want %v", c.Loop, tt.loop)
+			}
+			if d := c.Frames[0].Duration; d != 0.1 {
+				t.Errorf("duration %v, want 0.1", d)
+			}
+		})
+	}
+}
+
+func TestAsepriteUntagged(t *testing.T) {
+	sheet, err := ParseAseprite([]byte(aseprite("", "")), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := sheet.Clip("default")
+	if c == nil || !c.Loop {
+		t.Fatalf("default clip = %+v", c)
+	}
+	if got, want := indices(c), []int{0, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("plays frames %v, want %v", got, want)
+	}
+}
+
+func TestAsepriteHashOrder(t *testing.T) {
+	// The hash layout keeps the export's frame order, not the keys' order.
+	data := `{"frames": {
+		"z": {"frame": {"x": 32, "y": 0, "w": 16, "h": 16}, "duration": 100},
+		"a": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "duration": 100},
+		"m": {"frame": {"x": 48, "y": 0, "w": 16, "h": 16}, "duration": 100}
+	}, "meta": {"size": {"w": 64, "h": 16}}}`
+	sheet, err := ParseAseprite([]byte(data), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indices(sheet.Clips[0]), []int{2, 0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("plays frames %v, want %v", got, want)
+	}
+}
+
+func TestAsepriteTrim(t *testing.T) {
+	// Trimmed 8x4 and 16x16 frames cut from a 32x32 canvas.
+	data := `{"frames": [
+		{"frame": {"x": 0, "y": 0, "w": 8, "h": 4}, "trimmed": true,
+		 "spriteSourceSize": {"x": 20, "y": 2, "w": 8, "h": 4}, "sourceSize": {"w": 32, "h": 32}},
+		{"frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "trimmed": true,
+		 "spriteSourceSize": {"x": 8, "y": 8, "w": 16, "h": 16}, "sourceSize": {"w": 32, "h": 32}},
+		{"frame": {"x": 32, "y": 0, "w": 16, "h": 16}, "trimmed": false,
+		 "spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}}
+	], "meta": {}}`
+	sheet, err := ParseAseprite([]byte(data), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := sheet.Clips[0].Frames
+	tests := []struct {
+		rect   pixel.Rect
+		offset pixel.Vec
+	}{
+		// Right of centre and near the top of the canvas.
+		{pixel.R(0, 12, 8, 16), pixel.V(8, 12)},
+		// Centred.
+		{pixel.R(16, 0, 32, 16), pixel.ZV},
+		{pixel.R(32, 0, 48, 16), pixel.ZV},
+	}
+	for i, tt := range tests {
+		if frames[i].Rect != tt.rect {
+			t.Errorf("frame %d at %v, want %v", i, frames[i].Rect, tt.rect)
+		}
+		if frames[i].Offset != tt.offset {
+			t.Errorf("frame %d offset %v, want %v", i, frames[i].Offset, tt.offset)
+		}
+	}
+}
+
+func TestAsepriteSlices(t *testing.T) {
+	// The hitbox moves at frame 2; the mouth is only keyed on frame 1.
+	slices := `{"name": "hitbox", "keys": [
+		{"frame": 0, "bounds": {"x": 0, "y": 0, "w": 16, "h": 8}},
+		{"frame": 2, "bounds": {"x": 4, "y": 4, "w": 8, "h": 8}}
+	]}, {"name": "mouth", "keys": [
+		{"frame": 1, "bounds": {"x": 12, "y": 10, "w": 2, "h": 2}}
+	]}`
+	sheet, err := ParseAseprite([]byte(aseprite("", slices)), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := sheet.Clip("default").Frames
+
+	top := pixel.R(-8, 0, 8, 8)
+	middle := pixel.R(-4, -4, 4, 4)
+	tests := []struct {
+		frame  int
+		slices map[string]pixel.Rect
+	}{
+		{0, map[string]pixel.Rect{"hitbox": top}},
+		{1, map[string]pixel.Rect{"hitbox": top, "mouth": pixel.R(4, -4, 6, -2)}},
+		{2, map[string]pixel.Rect{"hitbox": middle, "mouth": pixel.R(4, -4, 6, -2)}},
+		{3, map[string]pixel.Rect{"hitbox": middle, "mouth": pixel.R(4, -4, 6, -2)}},
+	}
+	for _, tt := range tests {
+		if got := frames[tt.frame].Slices; !reflect.DeepEqual(got, tt.slices) {
+			t.Errorf("frame %d slices %v, want %v", tt.frame, got, tt.slices)
+		}
+	}
+}
+
+func TestAsepriteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no frames member", `{"meta": {}}`, `missing "frames"`},
+		{"no frames", `{"frames": [], "meta": {}}`, "no frames"},
+		{"wrong size", `{"frames": [], "meta": {"size": {"w": 32, "h": 32}}}`, "export is for a 32x32 image but the image is 64x16"},
+		{"rotated", `{"frames": [{"filename": "r", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "rotated": true}], "meta": {}}`, "rotated"},
+		{"outside", `{"frames": [{"filename": "o", "frame": {"x": 56, "y": 0, "w": 16, "h": 16}}], "meta": {}}`, "frame 0 (o) is not inside"},
+		{"tag range", aseprite(`{"name": "t", "from": 2, "to": 5}`, ""), `tag "t" covers frames 2-5, but there are 4 frames`},
+		{"tag twice", aseprite(`{"name": "t", "from": 0, "to": 1}, {"name": "t", "from": 2, "to": 3}`, ""), `tag "t" is defined twice`},
+		{"direction", aseprite(`{"name": "t", "from": 0, "to": 1, "direction": "sideways"}`, ""), `unknown direction "sideways"`},
+		{"repeat", aseprite(`{"name": "t", "from": 0, "to": 1, "repeat": "lots"}`, ""), `bad repeat count "lots"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), row)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package anim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// aseRect is a rectangle in Aseprite's image coordinates, measured down
+// from the top-left corner.
+type aseRect struct {
+	X, Y, W, H int
+}
+
+type aseFrame struct {
+	Filename         string  `json:"filename"`
+	Frame            aseRect `json:"frame"`
+	Rotated          bool    `json:"rotated"`
+	Trimmed          bool    `json:"trimmed"`
+	SpriteSourceSize aseRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W, H int
+	} `json:"sourceSize"`
+	Duration int `json:"duration"` // milliseconds
+}
+
+type aseExport struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		App  string `json:"app"`
+		Size struct {
+			W, H int
+		} `json:"size"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"`
+		} `json:"frameTags"`
+		Slices []struct {
+			Name string `json:"name"`
+			Keys []struct {
+				Frame  int     `json:"frame"`
+				Bounds aseRect `json:"bounds"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+// ParseAseprite reads the JSON that Aseprite's "Export Sprite Sheet" writes
+// alongside pic, in either its hash or array layout. Each frame tag becomes
+// a clip that plays in the tag's direction and loops unless the tag sets a
+// repeat count; a sheet without tags plays every frame as one looping clip
+// called "default". Trimmed frames keep their place within the untrimmed
+// frame through Frame.Offset, and slices are attached to the frames they
+// are keyed on.
+func ParseAseprite(data []byte, pic *pixel.PictureData) (*Sheet, error) {
+	var ex aseExport
+	if err := json.Unmarshal(data, &ex); err != nil {
+		return nil, err
+	}
+	frames, err := aseFrames(ex.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	b := pic.Bounds()
+	if ex.Meta.Size.W != 0 && (float64(ex.Meta.Size.W) != b.W() || float64(ex.Meta.Size.H) != b.H()) {
+		return nil, fmt.Errorf("export is for a %dx%d image but the image is %vx%v", ex.Meta.Size.W, ex.Meta.Size.H, b.W(), b.H())
+	}
+
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	all := make([]Frame, len(frames))
+	for i, f := range frames {
+		r := f.Frame
+		switch {
+		case f.Rotated:
+			fail("frame %d (%s) is rotated, which isn't supported", i, f.Filename)
+			continue
+		case r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 || float64(r.X+r.W) > b.W() || float64(r.Y+r.H) > b.H():
+			fail("frame %d (%s) is not inside the %vx%v image", i, f.Filename, b.W(), b.H())
+			continue
+		}
+		x, y := b.Min.X+float64(r.X), b.Max.Y-float64(r.Y+r.H)
+		all[i] = Frame{
+			Rect:     pixel.R(x, y, x+float64(r.W), y+float64(r.H)),
+			Duration: float64(f.Duration) / 1000,
+		}
+		if f.Trimmed {
+			src, full := f.SpriteSourceSize, f.SourceSize
+			all[i].Offset = pixel.V(
+				float64(src.X)+float64(src.W)/2-float64(full.W)/2,
+				float64(full.H)/2-float64(src.Y)-float64(src.H)/2,
+			)
+		}
+	}
+
+	// A slice key holds from its frame until the next key.
+	for _, s := range ex.Meta.Slices {
+		for k, key := range s.Keys {
+			end := len(all)
+			if k+1 < len(s.Keys) {
+				end = s.Keys[k+1].Frame
+			}
+			for i := max(key.Frame, 0); i < min(end, len(all)); i++ {
+				f := frames[i]
+				full := pixel.V(float64(f.SourceSize.W), float64(f.SourceSize.H))
+				if full == pixel.ZV {
+					full = pixel.V(float64(f.Frame.W), float64(f.Frame.H))
+				}
+				bb := key.Bounds
+				// Relative to the centre of the untrimmed frame, y up.
+				lo := pixel.V(float64(bb.X)-full.X/2, full.Y/2-float64(bb.Y+bb.H))
+				if all[i].Slices == nil {
+					all[i].Slices = make(map[string]pixel.Rect)
+				}
+				all[i].Slices[s.Name] = pixel.Rect{Min: lo, Max: lo.Add(pixel.V(float64(bb.W), float64(bb.H)))}
+			}
+		}
+	}
+
+	sheet := &Sheet{Picture: pic}
+	for _, tag := range ex.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(all) || tag.From > tag.To {
+			fail("tag %q covers frames %d-%d, but there are %d frames", tag.Name, tag.From, tag.To, len(all))
+			continue
+		}
+		if sheet.Clip(tag.Name) != nil {
+			fail("tag %q is defined twice", tag.Name)
+			continue
+		}
+		order, err := aseOrder(tag.From, tag.To, tag.Direction)
+		if err != nil {
+			fail("tag %q: %v", tag.Name, err)
+			continue
+		}
+		clip := &Clip{Name: tag.Name, Loop: true}
+		repeat := 1
+		if tag.Repeat != "" && tag.Repeat != "0" {
+			n, err := strconv.Atoi(tag.Repeat)
+			if err != nil || n < 0 {
+				fail("tag %q has a bad repeat count %q", tag.Name, tag.Repeat)
+				continue
+			}
+			clip.Loop, repeat = false, n
+		}
+		for ; repeat > 0; repeat-- {
+			for _, i := range order {
+				clip.Frames = append(clip.Frames, all[i])
+			}
+		}
+		sheet.Clips = append(sheet.Clips, clip)
+	}
+	if len(ex.Meta.FrameTags) == 0 && len(all) > 0 {
+		sheet.Clips = append(sheet.Clips, &Clip{Name: "default", Frames: all, Loop: true})
+	}
+	if len(all) == 0 {
+		fail("no frames")
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return sheet, nil
+}
+
+// aseFrames decodes the "frames" member, which Aseprite writes either as an
+// array or as an object keyed by filename. The object's order is the frame
+// order, so it is read token by token rather than into a map.
+func aseFrames(raw json.RawMessage) ([]aseFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errors.New(`missing "frames"`)
+	}
+	if raw[0] == '[' {
+		var frames []aseFrame
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var frames []aseFrame
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f aseFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename = key.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// aseOrder lists the frames a tag plays, in order, for one pass.
+func aseOrder(from, to int, direction string) ([]int, error) {
+	var fwd []int
+	for i := from; i <= to; i++ {
+		fwd = append(fwd, i)
+	}
+	rev := make([]int, len(fwd))
+	for i, f := range fwd {
+		rev[len(fwd)-1-i] = f
+	}
+	// A ping-pong doesn't repeat the frames it turns around on.
+	inner := func(s []int) []int {
+		if len(s) <= 2 {
+			return nil
+		}
+		return s[1 : len(s)-1]
+	}
+
+	switch direction {
+	case "", "forward":
+		return fwd, nil
+	case "reverse":
+		return rev, nil
+	case "pingpong":
+		return append(fwd, inner(rev)...), nil
+	case "pingpong_reverse":
+		return append(rev, inner(fwd)...), nil
+	}
+	return nil, fmt.Errorf("unknown direction %q", direction)
+}
@@ -0,0 +1,95 @@
+package anim
+
+import "github.com/gopxl/pixel/v2"
+
+// Player steps through the clips of a sheet.
+type Player struct {
+	sheet    *Sheet
+	fallback float64 // duration of frames that don't set one
+
+	clip    *Clip
+	frame   int
+	time    float64 // seconds spent on the current frame
+	wrapped bool    // a looping clip has been round at least once
+}
+
+// NewPlayer returns a player showing the first frame of the sheet's first
+// clip. Frames without a duration of their own last defaultDuration.
+func NewPlayer(s *Sheet, defaultDuration float64) *Player {
+	return &Player{sheet: s, fallback: defaultDuration, clip: s.Clips[0]}
+}
+
+// Play switches to the clip called name from its first frame, or to the
+// sheet's first clip if there is no such clip. Asking for the clip that is
+// already playing leaves it running, so Play can be called every step.
+func (p *Player) Play(name string) {
+	c := p.sheet.Clip(name)
+	if c == nil {
+		c = p.sheet.Clips[0]
+	}
+	if c == p.clip {
+		return
+	}
+	p.clip, p.frame, p.time, p.wrapped = c, 0, 0, false
+}
+
+// Update advances the current clip by dt seconds. A looping clip wraps
+// around; any other clip stops on its last frame.
+func (p *Player) Update(dt float64) {
+	p.time += dt
+	for {
+		d := p.duration()
+		if d <= 0 || p.time < d {
+			return
+		}
+		if p.frame == len(p.clip.Frames)-1 && !p.clip.Loop {
+			p.time = d
+			return
+		}
+		p.time -= d
+		p.frame = (p.frame + 1) % len(p.clip.Frames)
+		if p.frame == 0 {
+			p.wrapped = true
+		}
+	}
+}
+
+func (p *Player) duration() float64 {
+	if d := p.clip.Frames[p.frame].Duration; d > 0 {
+		return d
+	}
+	return p.fallback
+}
+
+// Played reports whether the current clip has shown every frame for its
+// full duration at least once, whether or not it loops.
+func (p *Player) Played() bool { return p.wrapped || p.Done() }
+
+// Picture returns the picture the sheet's frames are cut from.
+func (p *Player) Picture() *pixel.PictureData { return p.sheet.Picture }
+
+// Frame returns the area of the picture to show now.
+func (p *Player) Frame() pixel.Rect { return p.clip.Frames[p.frame].Rect }
+
+// Offset returns how far the current frame is drawn from the sprite's
+// position.
+func (p *Player) Offset() pixel.Vec { return p.clip.Frames[p.frame].Offset }
+
+// Slice returns the current frame's slice called name, relative to the
+// sprite's position.
+func (p *Player) Slice(name string) (pixel.Rect, bool) {
+	r, ok := p.clip.Frames[p.frame].Slices[name]
+	return r, ok
+}
+
+// Clip returns the name of the playing clip.
+func (p *Player) Clip() string { return p.clip.Name }
+
+// Index returns the position of the current frame within its clip.
+func (p *Player) Index() int { return p.frame }
+
+// Done reports whether a clip that doesn't loop has finished its last
+// frame. Looping clips are never done.
+func (p *Player) Done() bool {
+	return !p.clip.Loop && p.frame == len(p.clip.Frames)-1 && p.time >= p.duration()
+}
@@ -0,0 +1,110 @@
+// Package assets bundles the game's default sprites, sounds and word list
+// into the binary and loads them through an fs.FS, so the game runs from any
+// working directory. An override directory laid out like this one can
+// replace any of the files.
+//
+// Recordings of the words, such as words/ship.wav, sit beside their images
+// and are bundled along with them. Letter recordings (letters/) and arenas
+// (arenas/) aren't bundled: the game only finds them in an override
+// directory.
+package assets
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+//go:embed gem.png unicorn-sheet.png unicorn-sheet.json words.txt words sounds
+var Embedded embed.FS
+
+// Manager loads game assets from a filesystem.
+type Manager struct {
+	fsys fs.FS
+}
+
+// NewManager returns a manager over the embedded assets. If overrideDir is
+// not empty, files found there take precedence over the embedded ones.
+func NewManager(overrideDir string) *Manager {
+	if overrideDir == "" {
+		return &Manager{fsys: Embedded}
+	}
+	return &Manager{fsys: overlay{upper: os.DirFS(overrideDir), lower: Embedded}}
+}
+
+// NewManagerFS returns a manager reading only from fsys.
+func NewManagerFS(fsys fs.FS) *Manager {
+	return &Manager{fsys: fsys}
+}
+
+// FS returns the filesystem assets are read from.
+func (m *Manager) FS() fs.FS { return m.fsys }
+
+// Replace returns a manager that reads the asset called name from the file
+// at path instead, and everything else from m.
+func (m *Manager) Replace(name, path string) *Manager {
+	return &Manager{fsys: replaced{name: name, path: path, lower: m.fsys}}
+}
+
+// replaced serves one name from a file elsewhere on disk.
+type replaced struct {
+	name, path string
+	lower      fs.FS
+}
+
+func (r replaced) Open(name string) (fs.File, error) {
+	if name == r.name {
+		return os.Open(r.path)
+	}
+	return r.lower.Open(name)
+}
+
+func (r replaced) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(r.lower, name)
+}
+
+// overlay is a read-only union of two filesystems where upper shadows lower.
+type overlay struct {
+	upper, lower fs.FS
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.lower.Open(name)
+}
+
+// ReadDir merges the directory listings of both layers, preferring upper's
+// entry when a name exists in both. A directory missing from one layer is
+// fine; failing to read one that is there is an error, as with Open.
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	upper, uerr := fs.ReadDir(o.upper, name)
+	if uerr != nil && !errors.Is(uerr, fs.ErrNotExist) {
+		return nil, uerr
+	}
+	lower, lerr := fs.ReadDir(o.lower, name)
+	if lerr != nil && (uerr != nil || !errors.Is(lerr, fs.ErrNotExist)) {
+		return nil, lerr
+	}
+
+	byName := make(map[string]fs.DirEntry, len(upper)+len(lower))
+	for _, e := range lower {
+		byName[e.Name()] = e
+	}
+	for _, e := range upper {
+		byName[e.Name()] = e
+	}
+	entries := make([]fs.DirEntry, 0, len(byName))
+	for _, e := range byName {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
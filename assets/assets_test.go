@@ -0,0 +1,65 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// brokenFS fails every read with a permission error.
+type brokenFS struct{}
+
+func (brokenFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestOverlayReadDir(t *testing.T) {
+	files := fstest.MapFS{
+		"words/cat.png": {},
+		"words/dog.png": {},
+		"sounds/a.wav":  {},
+	}
+	override := fstest.MapFS{
+		"words/dog.png":  {Data: []byte("mine")},
+		"words/frog.png": {},
+		"letters/A.wav":  {},
+	}
+	tests := []struct {
+		name         string
+		upper, lower fs.FS
+		dir          string
+		want         string // the names listed
+		err          error  // the error expected instead, if any
+	}{
+		{"merged", override, files, "words", "cat.png dog.png frog.png", nil},
+		{"lower only", override, files, "sounds", "a.wav", nil},
+		{"upper only", override, files, "letters", "A.wav", nil},
+		{"neither", override, files, "arenas", "", fs.ErrNotExist},
+		{"upper unreadable", brokenFS{}, files, "words", "", fs.ErrPermission},
+		{"lower unreadable", override, brokenFS{}, "words", "", fs.ErrPermission},
+		{"lower unreadable, upper missing", override, brokenFS{}, "sounds", "", fs.ErrPermission},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := fs.ReadDir(overlay{upper: tt.upper, lower: tt.lower}, tt.dir)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("listed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
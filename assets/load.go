@@ -0,0 +1,255 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/anim"
+	"unicorn-toots/audio"
+	"unicorn-toots/tilemap"
+)
+
+func (m *Manager) decode(name string) (image.Image, error) {
+	f, err := m.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return img, nil
+}
+
+// Spritesheet loads an animated sheet. Its clips are described by a sidecar
+// file with the same name ending in .json, in package anim's format or as
+// exported by Aseprite; a sheet without one is read as a horizontal strip of
+// 32x32 frames played as a single looping "walk" clip.
+func (m *Manager) Spritesheet(name string) (*anim.Sheet, error) {
+	img, err := m.decode(name)
+	if err != nil {
+		return nil, err
+	}
+	pic := pixel.PictureDataFromImage(img)
+
+	meta := strings.TrimSuffix(name, path.Ext(name)) + ".json"
+	data, err := fs.ReadFile(m.fsys, meta)
+	if errors.Is(err, fs.ErrNotExist) {
+		sheet, err := anim.Strip(pic, 32, "walk")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return sheet, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := anim.Parse(data, pic)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", meta, err)
+	}
+	return sheet, nil
+}
+
+// Sprite loads a whole image as a single sprite.
+func (m *Manager) Sprite(name string) (*pixel.Sprite, error) {
+	img, err := m.decode(name)
+	if err != nil {
+		return nil, err
+	}
+	pic := pixel.PictureDataFromImage(img)
+	return pixel.NewSprite(pic, pic.Bounds()), nil
+}
+
+// Words loads a word list with one word per line, upper-cased. A list with
+// no words in it is an error.
+func (m *Manager) Words(name string) ([]string, error) {
+	data, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		w := strings.TrimSpace(line)
+		if w != "" {
+			words = append(words, strings.ToUpper(w))
+		}
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s: no words", name)
+	}
+	return words, nil
+}
+
+// WordImages loads every PNG in dir, keyed by its upper-cased base name.
+// Images that fail to load are replaced by a placeholder and reported in the
+// returned error, so one bad file doesn't lose the rest.
+func (m *Manager) WordImages(dir string) (map[string]*pixel.Sprite, error) {
+	images := make(map[string]*pixel.Sprite)
+	entries, err := fs.ReadDir(m.fsys, dir)
+	if err != nil {
+		return images, err
+	}
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".png" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".png")
+		spr, err := m.Sprite(path.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			spr = PlaceholderSprite()
+		}
+		images[strings.ToUpper(name)] = spr
+	}
+	return images, errors.Join(errs...)
+}
+
+// Tilemap loads a map made in Tiled, saved as .tmx, .tmj or .json, along
+// with any external tilesets and the tileset images it uses.
+func (m *Manager) Tilemap(name string) (*tilemap.Map, error) {
+	data, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := tilemap.Parse(name, data, func(name string) ([]byte, error) {
+		return fs.ReadFile(m.fsys, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ts := range tm.Tilesets {
+		if ts.Image == "" {
+			return nil, fmt.Errorf("%s: tileset %q has no image; image collection tilesets aren't supported", name, ts.Name)
+		}
+		img, err := m.decode(ts.Image)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		ts.Picture = pixel.PictureDataFromImage(img)
+	}
+	return tm, nil
+}
+
+// Arenas loads every map in dir, in name order. Maps that fail to load are
+// left out and reported in the returned error; a missing directory just
+// means there are no arenas. Tilesets kept in dir should be saved as .tsx or
+// .tsj so they aren't mistaken for maps.
+func (m *Manager) Arenas(dir string) ([]*tilemap.Map, error) {
+	entries, err := fs.ReadDir(m.fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		maps []*tilemap.Map
+		errs []error
+	)
+	for _, entry := range entries {
+		switch path.Ext(entry.Name()) {
+		case ".tmx", ".tmj", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		tm, err := m.Tilemap(path.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		maps = append(maps, tm)
+	}
+	return maps, errors.Join(errs...)
+}
+
+// Placeholder returns a 32x32 magenta and black checkerboard, drawn in place
+// of images that failed to load so the gap is obvious rather than invisible.
+func Placeholder() *pixel.PictureData {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	magenta := color.RGBA{255, 0, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if (x/8+y/8)%2 == 0 {
+				img.SetRGBA(x, y, magenta)
+			} else {
+				img.SetRGBA(x, y, black)
+			}
+		}
+	}
+	return pixel.PictureDataFromImage(img)
+}
+
+// PlaceholderSprite returns the placeholder image as a sprite.
+func PlaceholderSprite() *pixel.Sprite {
+	pic := Placeholder()
+	return pixel.NewSprite(pic, pic.Bounds())
+}
+
+// Sound loads and decodes one sound file.
+func (m *Manager) Sound(name string) (*audio.Sound, error) {
+	f, err := m.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return audio.Decode(name, f)
+}
+
+// Sounds loads every .wav and .ogg file in dir, keyed by lowercase file name
+// without the extension. A missing dir means the game is played silently,
+// so it yields no sounds rather than an error. Files that fail to decode are
+// left out.
+func (m *Manager) Sounds(dir string) (map[string]*audio.Sound, error) {
+	return m.soundsIn(dir, strings.ToLower)
+}
+
+// Speech loads recordings of words or letters being said from dir, such as
+// words/ship.wav, keyed by the uppercase word or letter, as words are in
+// the word list. Like Sounds, a missing dir yields none.
+func (m *Manager) Speech(dir string) (map[string]*audio.Sound, error) {
+	return m.soundsIn(dir, strings.ToUpper)
+}
+
+// soundsIn loads the sound files in dir, keyed by key applied to their
+// names without the extension.
+func (m *Manager) soundsIn(dir string, key func(string) string) (map[string]*audio.Sound, error) {
+	entries, err := fs.ReadDir(m.fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sounds := make(map[string]*audio.Sound)
+	var errs []error
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if entry.IsDir() || !slices.Contains(audio.Extensions, ext) {
+			continue
+		}
+		s, err := m.Sound(path.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sounds[key(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))] = s
+	}
+	return sounds, errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/audio"
+)
+
+// Reload carries freshly loaded word data from a Watcher. Fields for files
+// that didn't change are nil.
+type Reload struct {
+	Words      []string
+	WordImages map[string]*pixel.Sprite
+	WordSounds map[string]*audio.Sound
+	Err        error
+}
+
+// Watcher polls the word list and the word directory's images and
+// recordings for changes and reloads them in the background. Results are
+// delivered on a channel so the game can apply them between frames.
+type Watcher struct {
+	m       *Manager
+	words   string
+	dir     string
+	updates chan Reload
+	stop    chan struct{}
+}
+
+// fileStamp is what a poll compares to notice a file changed.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// NewWatcher starts polling the word list file and the word directory dir
+// through m every interval.
+func NewWatcher(m *Manager, words, dir string, interval time.Duration) *Watcher {
+	w := &Watcher{
+		m:       m,
+		words:   words,
+		dir:     dir,
+		updates: make(chan Reload, 1),
+		stop:    make(chan struct{}),
+	}
+	go w.poll(interval)
+	return w
+}
+
+// Updates returns the channel reloads are delivered on.
+func (w *Watcher) Updates() <-chan Reload { return w.updates }
+
+// Stop ends polling.
+func (w *Watcher) Stop() { close(w.stop) }
+
+func (w *Watcher) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	wordStamp := w.stampFile(w.words)
+	imageStamp := w.stampDir(w.dir, ".png")
+	soundStamp := w.stampDir(w.dir, audio.Extensions...)
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		var r Reload
+		var errs []error
+		changed := false
+
+		if s := w.stampFile(w.words); s != wordStamp {
+			wordStamp = s
+			changed = true
+			words, err := w.m.Words(w.words)
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				r.Words = words
+			}
+		}
+		if s := w.stampDir(w.dir, ".png"); !sameStamps(s, imageStamp) {
+			imageStamp = s
+			changed = true
+			images, err := w.m.WordImages(w.dir)
+			errs = append(errs, err)
+			r.WordImages = images
+		}
+		if s := w.stampDir(w.dir, audio.Extensions...); !sameStamps(s, soundStamp) {
+			soundStamp = s
+			changed = true
+			sounds, err := w.m.Speech(w.dir)
+			errs = append(errs, err)
+			r.WordSounds = sounds
+		}
+		if !changed {
+			continue
+		}
+		r.Err = errors.Join(errs...)
+
+		select {
+		case w.updates <- r:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *Watcher) stampFile(name string) fileStamp {
+	info, err := fs.Stat(w.m.fsys, name)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: info.ModTime(), size: info.Size()}
+}
+
+// stampDir stamps the files in dir with one of the extensions exts.
+func (w *Watcher) stampDir(dir string, exts ...string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	entries, err := fs.ReadDir(w.m.fsys, dir)
+	if err != nil {
+		return stamps
+	}
+	for _, e := range entries {
+		if e.IsDir() || !slices.Contains(exts, strings.ToLower(path.Ext(e.Name()))) {
+			continue
+		}
+		stamps[e.Name()] = w.stampFile(path.Join(dir, e.Name()))
+	}
+	return stamps
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, s := range a {
+		if b[name] != s {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package assets
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const interval = 5 * time.Millisecond
+
+// pngData encodes a blank w by h image.
+func pngData(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeFile replaces name with data all at once, so a poll never sees the
+// file half written.
+func writeFile(t *testing.T, name string, data []byte) {
+	t.Helper()
+	tmp := filepath.Join(t.TempDir(), filepath.Base(name))
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextReload waits for w's next reload.
+func nextReload(t *testing.T, w *Watcher) Reload {
+	t.Helper()
+	select {
+	case r := <-w.Updates():
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload")
+		return Reload{}
+	}
+}
+
+// noReload checks that w delivers nothing over a few polls.
+func noReload(t *testing.T, w *Watcher) {
+	t.Helper()
+	select {
+	case r := <-w.Updates():
+		t.Fatalf("unexpected reload %+v", r)
+	case <-time.After(10 * interval):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	words := filepath.Join(dir, "words.txt")
+	images := filepath.Join(dir, "words")
+	writeFile(t, words, []byte("cat\ndog\n"))
+	if err := os.Mkdir(images, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(images, "cat.png"), pngData(t, 4, 4))
+
+	w := NewWatcher(NewManagerFS(os.DirFS(dir)), "words.txt", "words", interval)
+	defer w.Stop()
+	noReload(t, w)
+
+	steps := []struct {
+		name   string
+		edit   func()
+		words  []string // nil if the word list shouldn't be reloaded
+		images []string // names expected, nil if images shouldn't be reloaded
+		sounds bool     // whether recordings should be reloaded
+		err    string   // part of the error expected, if any
+	}{
+		{"word list", func() { writeFile(t, words, []byte("cat\ndog\nfrog\n")) },
+			[]string{"CAT", "DOG", "FROG"}, nil, false, ""},
+		{"new image", func() { writeFile(t, filepath.Join(images, "dog.png"), pngData(t, 4, 4)) },
+			nil, []string{"CAT", "DOG"}, false, ""},
+		{"changed image", func() { writeFile(t, filepath.Join(images, "dog.png"), pngData(t, 8, 8)) },
+			nil, []string{"CAT", "DOG"}, false, ""},
+		// Files the game doesn't read are left alone.
+		{"other file", func() { writeFile(t, filepath.Join(images, "notes.txt"), []byte("hi")) },
+			nil, nil, false, ""},
+		{"empty word list", func() { writeFile(t, words, []byte("\n")) },
+			nil, nil, false, "no words"},
+		{"broken image", func() { writeFile(t, filepath.Join(images, "frog.png"), []byte("not a png")) },
+			nil, []string{"CAT", "DOG", "FROG"}, false, "frog.png"},
+		{"broken recording", func() { writeFile(t, filepath.Join(images, "cat.wav"), []byte("not a wav")) },
+			nil, nil, true, "cat.wav"},
+	}
+	for _, st := range steps {
+		st.edit()
+		if st.words == nil && st.images == nil && !st.sounds && st.err == "" {
+			noReload(t, w)
+			continue
+		}
+		r := nextReload(t, w)
+		if strings.Join(r.Words, " ") != strings.Join(st.words, " ") {
+			t.Errorf("%s: reloaded words %q, want %q", st.name, r.Words, st.words)
+		}
+		var names []string
+		for _, n := range []string{"CAT", "DOG", "FROG"} {
+			if _, ok := r.WordImages[n]; ok {
+				names = append(names, n)
+			}
+		}
+		if (r.WordImages == nil) != (st.images == nil) || len(r.WordImages) != len(names) ||
+			strings.Join(names, " ") != strings.Join(st.images, " ") {
+			t.Errorf("%s: reloaded images %v, want %q", st.name, r.WordImages, st.images)
+		}
+		if (r.WordSounds != nil) != st.sounds {
+			t.Errorf("%s: reloaded recordings %v, want reloaded %v", st.name, r.WordSounds, st.sounds)
+		}
+		switch {
+		case st.err == "" && r.Err != nil:
+			t.Errorf("%s: unexpected error: %v", st.name, r.Err)
+		case st.err != "" && (r.Err == nil || !strings.Contains(r.Err.Error(), st.err)):
+			t.Errorf("%s: error %v, want it to mention %q", st.name, r.Err, st.err)
+		}
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	dir := t.TempDir()
+	words := filepath.Join(dir, "words.txt")
+	writeFile(t, words, []byte("cat\n"))
+
+	w := NewWatcher(NewManagerFS(os.DirFS(dir)), "words.txt", "words", interval)
+	w.Stop()
+	writeFile(t, words, []byte("cat\ndog\n"))
+	noReload(t, w)
+}
@@ -0,0 +1,81 @@
+// Package audio decodes the game's sound files and mixes them for playback.
+// The game only ever asks a Backend to play a sound by name, so it can run
+// silently on a Null backend, or on a Recorder that notes what it was asked
+// to play, without a sound device.
+package audio
+
+import "fmt"
+
+// Channel is a group of sounds whose volume is set together.
+type Channel int
+
+const (
+	Effects Channel = iota // short cues for things happening in play
+	Speech                 // words and letters read out
+
+	numChannels
+)
+
+var channelNames = [...]string{Effects: "effects", Speech: "speech"}
+
+func (c Channel) String() string {
+	if c < 0 || c >= numChannels {
+		return fmt.Sprintf("Channel(%d)", int(c))
+	}
+	return channelNames[c]
+}
+
+// Backend plays sounds by name. Names are sound file names without their
+// extension; asking for a sound that wasn't loaded plays nothing.
+type Backend interface {
+	Play(name string, ch Channel)
+	// Stop cuts off everything still playing on ch.
+	Stop(ch Channel)
+}
+
+// Null is a Backend that plays nothing.
+type Null struct{}
+
+func (Null) Play(string, Channel) {}
+func (Null) Stop(Channel)         {}
+
+// Event is one request made of a Recorder.
+type Event struct {
+	Name    string  // the sound asked for, or "" for a Stop
+	Channel Channel // the channel it was played or stopped on
+}
+
+func (e Event) String() string {
+	if e.Name == "" {
+		return fmt.Sprintf("stop %s", e.Channel)
+	}
+	return fmt.Sprintf("%s on %s", e.Name, e.Channel)
+}
+
+// Recorder is a Backend that plays nothing but keeps a log of what it was
+// asked to do, so checks can tell which sounds a run of the game triggered.
+type Recorder struct {
+	Events []Event
+}
+
+func (r *Recorder) Play(name string, ch Channel) {
+	r.Events = append(r.Events, Event{Name: name, Channel: ch})
+}
+
+func (r *Recorder) Stop(ch Channel) {
+	r.Events = append(r.Events, Event{Channel: ch})
+}
+
+// Played returns the names of the sounds played, in order.
+func (r *Recorder) Played() []string {
+	var names []string
+	for _, e := range r.Events {
+		if e.Name != "" {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
+
+// Reset forgets everything recorded so far.
+func (r *Recorder) Reset() { r.Events = r.Events[:0] }
@@ -0,0 +1,283 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chunk is one RIFF chunk of a test WAV file.
+type chunk struct {
+	id   string
+	body []byte
+}
+
+// fmtChunk describes samples of the given format.
+func fmtChunk(tag uint16, channels, rate, bits int) chunk {
+	b := binary.LittleEndian.AppendUint16(nil, tag)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate*channels*bits/8))
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels*bits/8))
+	b = binary.LittleEndian.AppendUint16(b, uint16(bits))
+	return chunk{"fmt ", b}
+}
+
+// extensible wraps a format in WAVE_FORMAT_EXTENSIBLE.
+func extensible(tag uint16, channels, rate, bits int) chunk {
+	c := fmtChunk(wavExtensible, channels, rate, bits)
+	c.body = binary.LittleEndian.AppendUint16(c.body, 22)
+	c.body = binary.LittleEndian.AppendUint16(c.body, uint16(bits))
+	c.body = binary.LittleEndian.AppendUint32(c.body, 0)
+	c.body = binary.LittleEndian.AppendUint16(c.body, tag)
+	c.body = append(c.body, make([]byte, 14)...) // rest of the GUID
+	return c
+}
+
+// wavFile lays chunks out as a RIFF WAVE file, padding odd-sized ones.
+func wavFile(chunks ...chunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	for _, c := range chunks {
+		body.WriteString(c.id)
+		binary.Write(&body, binary.LittleEndian, uint32(len(c.body)))
+		body.Write(c.body)
+		if len(c.body)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	var f bytes.Buffer
+	f.WriteString("RIFF")
+	binary.Write(&f, binary.LittleEndian, uint32(body.Len()))
+	f.Write(body.Bytes())
+	return f.Bytes()
+}
+
+func le16(vs ...int16) []byte {
+	var b []byte
+	for _, v := range vs {
+		b = binary.LittleEndian.AppendUint16(b, uint16(v))
+	}
+	return b
+}
+
+func TestDecodeWAV(t *testing.T) {
+	float := func(vs ...float32) []byte {
+		var b []byte
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		file []byte
+		rate int
+		want [][2]float32
+	}{
+		{"8-bit mono", wavFile(fmtChunk(wavPCM, 1, 8000, 8), chunk{"data", []byte{128, 255, 0, 192}}),
+			8000, [][2]float32{{0, 0}, {127.0 / 128, 127.0 / 128}, {-1, -1}, {0.5, 0.5}}},
+		{"16-bit stereo", wavFile(fmtChunk(wavPCM, 2, 22050, 16), chunk{"data", le16(0, 16384, -32768, 32767)}),
+			22050, [][2]float32{{0, 0.5}, {-1, 32767.0 / 32768}}},
+		{"24-bit mono", wavFile(fmtChunk(wavPCM, 1, 44100, 24), chunk{"data", []byte{0, 0, 0x40, 0, 0, 0xc0, 0xff, 0xff, 0xff}}),
+			44100, [][2]float32{{0.5, 0.5}, {-0.5, -0.5}, {-1.0 / (1 << 23), -1.0 / (1 << 23)}}},
+		{"32-bit mono", wavFile(fmtChunk(wavPCM, 1, 44100, 32), chunk{"data", binary.LittleEndian.AppendUint32(nil, 1<<30)}),
+			44100, [][2]float32{{0.5, 0.5}}},
+		{"float stereo", wavFile(fmtChunk(wavFloat, 2, 48000, 32), chunk{"data", float(0.25, -0.75)}),
+			48000, [][2]float32{{0.25, -0.75}}},
+		{"extensible", wavFile(extensible(wavPCM, 1, 11025, 16), chunk{"data", le16(-16384)}),
+			11025, [][2]float32{{-0.5, -0.5}}},
+		{"surround", wavFile(fmtChunk(wavPCM, 3, 8000, 16), chunk{"data", le16(16384, -16384, 32767)}),
+			8000, [][2]float32{{0.5, -0.5}}},
+		{"odd chunk skipped", wavFile(chunk{"LIST", []byte("abc")}, fmtChunk(wavPCM, 1, 8000, 8), chunk{"data", []byte{192}}),
+			8000, [][2]float32{{0.5, 0.5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Decode("clip.WAV", bytes.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Rate != tt.rate {
+				t.Errorf("rate %d, want %d", s.Rate, tt.rate)
+			}
+			if !reflect.DeepEqual(s.Frames, tt.want) {
+				t.Errorf("frames %v, want %v", s.Frames, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeWAVTruncated(t *testing.T) {
+	// A data chunk claiming more than is there keeps the whole samples.
+	file := wavFile(fmtChunk(wavPCM, 1, 8000, 16), chunk{"data", le16(16384, 0)})
+	binary.LittleEndian.PutUint32(file[len(file)-8:], 100)
+	s, err := Decode("cut.wav", bytes.NewReader(file[:len(file)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]float32{{0.5, 0.5}}; !reflect.DeepEqual(s.Frames, want) {
+		t.Errorf("frames %v, want %v", s.Frames, want)
+	}
+	if s.Duration() != 125000 {
+		t.Errorf("duration %v, want 125µs", s.Duration())
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		want string
+	}{
+		{"extension", "clip.mp3", nil, "unsupported sound format"},
+		{"empty", "clip.wav", nil, "reading header"},
+		{"not riff", "clip.wav", []byte("RIFX\x00\x00\x00\x00WAVE"), "not a WAV file"},
+		{"no data", "clip.wav", wavFile(fmtChunk(wavPCM, 1, 8000, 8)), "no data chunk"},
+		{"data first", "clip.wav", wavFile(chunk{"data", []byte{1, 2}}, fmtChunk(wavPCM, 1, 8000, 8)), "data chunk before fmt chunk"},
+		{"short fmt", "clip.wav", wavFile(chunk{"fmt ", make([]byte, 8)}), "fmt chunk too short"},
+		{"12-bit", "clip.wav", wavFile(fmtChunk(wavPCM, 1, 8000, 12), chunk{"data", []byte{1, 2}}), "unsupported WAV encoding (format 1, 12 bits)"},
+		{"a-law", "clip.wav", wavFile(fmtChunk(6, 1, 8000, 8), chunk{"data", []byte{1}}), "format 6"},
+		{"no rate", "clip.wav", wavFile(fmtChunk(wavPCM, 1, 0, 8), chunk{"data", []byte{1}}), "bad sample rate 0"},
+		{"no channels", "clip.wav", wavFile(fmtChunk(wavPCM, 0, 8000, 8), chunk{"data", []byte{1}}), "bad channel count 0"},
+		{"bad ogg", "clip.ogg", []byte("not ogg"), "decoding clip.ogg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.file, bytes.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// steady returns a sound holding level on both sides for n frames.
+func steady(rate, n int, level float32) *Sound {
+	s := &Sound{Rate: rate, Frames: make([][2]float32, n)}
+	for i := range s.Frames {
+		s.Frames[i] = [2]float32{level, -level}
+	}
+	return s
+}
+
+func TestMixerVolume(t *testing.T) {
+	sounds := map[string]*Sound{
+		"cue":  steady(100, 10, 0.5),
+		"word": steady(100, 10, 0.25),
+	}
+	tests := []struct {
+		name           string
+		effects, voice float64
+		play           []Event
+		want           float32 // left sample; right is its negative
+	}{
+		{"full", 1, 1, []Event{{"cue", Effects}}, 0.5},
+		{"effects halved", 0.5, 1, []Event{{"cue", Effects}}, 0.25},
+		{"other channel down", 1, 0, []Event{{"cue", Effects}}, 0.5},
+		{"speech muted", 1, 0, []Event{{"word", Speech}}, 0},
+		{"both channels", 0.5, 1, []Event{{"cue", Effects}, {"word", Speech}}, 0.5},
+		{"clamped volume", 3, -1, []Event{{"cue", Effects}, {"word", Speech}}, 0.5},
+		{"clipped", 1, 1, []Event{{"cue", Effects}, {"cue", Effects}, {"cue", Speech}}, 1},
+		{"unknown sound", 1, 1, []Event{{"boing", Effects}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMixer(100, sounds)
+			m.SetVolume(Effects, tt.effects)
+			m.SetVolume(Speech, tt.voice)
+			for _, e := range tt.play {
+				m.Play(e.Name, e.Channel)
+			}
+			out := make([][2]float32, 4)
+			m.Mix(out)
+			for i, f := range out {
+				if f != [2]float32{tt.want, -tt.want} {
+					t.Fatalf("frame %d is %v, want ±%v", i, f, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMixerPlayback(t *testing.T) {
+	m := NewMixer(100, map[string]*Sound{
+		"short": steady(100, 3, 0.5),
+		"low":   steady(50, 2, 0.5), // plays twice as long at the mix's rate
+		"word":  steady(100, 100, 0.25),
+	})
+	m.Play("short", Effects)
+	m.Play("low", Effects)
+	m.Play("word", Speech)
+	if m.Playing() != 3 {
+		t.Fatalf("%d playing, want 3", m.Playing())
+	}
+
+	out := make([][2]float32, 5)
+	m.Mix(out)
+	var left []float32
+	for _, f := range out {
+		left = append(left, f[0])
+	}
+	if want := []float32{1, 1, 1, 0.75, 0.25}; !reflect.DeepEqual(left, want) {
+		t.Errorf("mixed %v, want %v", left, want)
+	}
+	if m.Playing() != 1 {
+		t.Errorf("%d playing after the short sounds ended, want 1", m.Playing())
+	}
+
+	m.Stop(Effects)
+	if m.Playing() != 1 {
+		t.Error("stopping effects stopped speech")
+	}
+	m.Stop(Speech)
+	if m.Playing() != 0 {
+		t.Error("speech still playing")
+	}
+
+	// Read never runs dry: with nothing playing it is silence.
+	p := make([]byte, 8*4)
+	for i := range p {
+		p[i] = 0xff
+	}
+	if n, err := m.Read(p); n != len(p) || err != nil {
+		t.Fatalf("Read = %d, %v", n, err)
+	}
+	if !bytes.Equal(p, make([]byte, len(p))) {
+		t.Errorf("silence read as % x", p)
+	}
+}
+
+func TestMixerVoiceLimit(t *testing.T) {
+	m := NewMixer(100, map[string]*Sound{"cue": steady(100, 10, 0.01)})
+	for i := 0; i < maxVoices+5; i++ {
+		m.Play("cue", Effects)
+	}
+	if m.Playing() != maxVoices {
+		t.Errorf("%d playing, want %d", m.Playing(), maxVoices)
+	}
+}
+
+func TestMixerSetSounds(t *testing.T) {
+	m := NewMixer(100, map[string]*Sound{"old": steady(100, 10, 0.5)})
+	m.Play("old", Speech)
+	m.SetSounds(map[string]*Sound{"new": steady(100, 10, 0.25)})
+	m.Play("old", Speech)
+	if m.Playing() != 1 {
+		t.Errorf("%d playing, want the first sound still going and no second", m.Playing())
+	}
+	m.Play("new", Speech)
+	out := make([][2]float32, 1)
+	m.Mix(out)
+	if out[0][0] != 0.75 {
+		t.Errorf("mixed %v, want the old and new sounds together", out[0])
+	}
+}
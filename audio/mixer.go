@@ -0,0 +1,149 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// maxVoices is how many sounds may play at once. Starting another cuts
+// off the oldest.
+const maxVoices = 16
+
+// Mixer is a Backend that plays loaded sounds together, resampled to one
+// output rate, with a volume for each channel. A sound device pulls the
+// mix from Read on its own goroutine while the game calls Play, so the
+// other methods are safe to call while it does.
+type Mixer struct {
+	mu     sync.Mutex
+	rate   int
+	sounds map[string]*Sound
+	volume [numChannels]float64
+	voices []*voice
+	buf    [][2]float32
+}
+
+// voice is one sound playing.
+type voice struct {
+	s    *Sound
+	ch   Channel
+	pos  float64 // frame of s being played, between frames when resampling
+	step float64 // frames of s per output frame
+}
+
+// NewMixer returns a mixer producing rate frames per second from sounds,
+// keyed by name, with every channel at full volume.
+func NewMixer(rate int, sounds map[string]*Sound) *Mixer {
+	m := &Mixer{rate: rate, sounds: sounds}
+	for ch := range m.volume {
+		m.volume[ch] = 1
+	}
+	return m
+}
+
+// Rate returns the mix's frames per second.
+func (m *Mixer) Rate() int { return m.rate }
+
+// SetVolume sets how loud ch plays, from 0 for silent to 1 for as loud as
+// its sounds were recorded.
+func (m *Mixer) SetVolume(ch Channel, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volume[ch] = math.Max(0, math.Min(v, 1))
+}
+
+// SetSounds replaces the sounds the mixer can play, such as after they were
+// reloaded. Sounds already playing carry on to their end.
+func (m *Mixer) SetSounds(sounds map[string]*Sound) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sounds = sounds
+}
+
+// Play starts the sound called name on ch, alongside whatever is already
+// playing.
+func (m *Mixer) Play(name string, ch Channel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sounds[name]
+	if s == nil || len(s.Frames) == 0 {
+		return
+	}
+	if len(m.voices) == maxVoices {
+		m.voices = append(m.voices[:0], m.voices[1:]...)
+	}
+	m.voices = append(m.voices, &voice{s: s, ch: ch, step: float64(s.Rate) / float64(m.rate)})
+}
+
+// Stop cuts off every sound playing on ch.
+func (m *Mixer) Stop(ch Channel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.voices[:0]
+	for _, v := range m.voices {
+		if v.ch != ch {
+			kept = append(kept, v)
+		}
+	}
+	clear(m.voices[len(kept):])
+	m.voices = kept
+}
+
+// Playing returns how many sounds are playing.
+func (m *Mixer) Playing() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.voices)
+}
+
+// Mix fills out with the next len(out) frames of the mix, clipped to -1..1.
+// Sounds that finish are dropped.
+func (m *Mixer) Mix(out [][2]float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(out)
+	kept := m.voices[:0]
+	for _, v := range m.voices {
+		vol := float32(m.volume[v.ch])
+		last := len(v.s.Frames) - 1
+		for i := range out {
+			j := int(v.pos)
+			if j > last {
+				break
+			}
+			// Blend between neighbouring frames when resampling.
+			a := v.s.Frames[j]
+			b := v.s.Frames[min(j+1, last)]
+			f := float32(v.pos - float64(j))
+			out[i][0] += (a[0] + (b[0]-a[0])*f) * vol
+			out[i][1] += (a[1] + (b[1]-a[1])*f) * vol
+			v.pos += v.step
+		}
+		if int(v.pos) <= last {
+			kept = append(kept, v)
+		}
+	}
+	clear(m.voices[len(kept):])
+	m.voices = kept
+	for i := range out {
+		out[i][0] = max(-1, min(out[i][0], 1))
+		out[i][1] = max(-1, min(out[i][1], 1))
+	}
+}
+
+// Read fills p with the mix as interleaved little-endian 32-bit floats,
+// left then right, as sound devices take it. The mix never ends: with
+// nothing playing it is silence.
+func (m *Mixer) Read(p []byte) (int, error) {
+	frames := len(p) / 8
+	if cap(m.buf) < frames {
+		m.buf = make([][2]float32, frames)
+	}
+	buf := m.buf[:frames]
+	m.Mix(buf)
+	for i, f := range buf {
+		binary.LittleEndian.PutUint32(p[i*8:], math.Float32bits(f[0]))
+		binary.LittleEndian.PutUint32(p[i*8+4:], math.Float32bits(f[1]))
+	}
+	return frames * 8, nil
+}
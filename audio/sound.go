@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// Sound is a decoded clip, held in memory as stereo frames. Mono clips are
+// played on both sides.
+type Sound struct {
+	Rate   int          // frames per second
+	Frames [][2]float32 // left and right samples, from -1 to 1
+}
+
+// Duration returns how long s plays for.
+func (s *Sound) Duration() time.Duration {
+	if s.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(len(s.Frames)) * time.Second / time.Duration(s.Rate)
+}
+
+// Extensions are the sound file types Decode understands.
+var Extensions = []string{".wav", ".ogg"}
+
+// Decode reads a sound file, choosing the format by name's extension: .wav
+// for uncompressed WAV or .ogg for Ogg Vorbis.
+func Decode(name string, r io.Reader) (*Sound, error) {
+	var (
+		s   *Sound
+		err error
+	)
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wav":
+		s, err = decodeWAV(r)
+	case ".ogg":
+		s, err = decodeOGG(r)
+	default:
+		return nil, fmt.Errorf("%s: unsupported sound format (want .wav or .ogg)", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return s, nil
+}
+
+func decodeOGG(r io.Reader) (*Sound, error) {
+	samples, format, err := oggvorbis.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return interleaved(samples, format.SampleRate, format.Channels)
+}
+
+// interleaved builds a sound from samples that take turns between
+// channels. Channels past the first two are dropped.
+func interleaved(samples []float32, rate, channels int) (*Sound, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("bad sample rate %d", rate)
+	}
+	if channels < 1 {
+		return nil, fmt.Errorf("bad channel count %d", channels)
+	}
+	s := &Sound{Rate: rate, Frames: make([][2]float32, len(samples)/channels)}
+	for i := range s.Frames {
+		f := samples[i*channels:]
+		s.Frames[i] = [2]float32{f[0], f[0]}
+		if channels > 1 {
+			s.Frames[i][1] = f[1]
+		}
+	}
+	return s, nil
+}
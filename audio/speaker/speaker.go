@@ -0,0 +1,47 @@
+// Package speaker plays an audio.Mixer through the computer's sound device.
+// It is kept apart from package audio because it needs the system's sound
+// libraries to build; nothing but the game window depends on it.
+package speaker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+
+	"unicorn-toots/audio"
+)
+
+// Rate is the frames per second the sound device is opened at.
+const Rate = 44100
+
+// latency is how much sound is buffered ahead of the device. Cues should
+// land on the frame they belong to, so it is kept short.
+const latency = 50 * time.Millisecond
+
+// Speaker is the sound device playing a mixer.
+type Speaker struct {
+	player *oto.Player
+}
+
+// Open starts the sound device playing m, which should mix at Rate. Only
+// one speaker can be open for the life of the program.
+func Open(m *audio.Mixer) (*Speaker, error) {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   m.Rate(),
+		ChannelCount: 2,
+		Format:       oto.FormatFloat32LE,
+		BufferSize:   latency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening sound device: %w", err)
+	}
+	<-ready
+	p := ctx.NewPlayer(m)
+	p.SetBufferSize(int(latency.Seconds()*float64(m.Rate())) * 8)
+	p.Play()
+	return &Speaker{player: p}, nil
+}
+
+// Close stops playing.
+func (s *Speaker) Close() error { return s.player.Close() }
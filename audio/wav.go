@@ -0,0 +1,121 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAV format tags.
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xFFFE
+)
+
+// decodeWAV reads a RIFF WAVE file of integer PCM at 8, 16, 24 or 32 bits,
+// or of 32-bit float samples.
+func decodeWAV(r io.Reader) (*Sound, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var (
+		haveFmt  bool
+		tag      uint16
+		channels int
+		rate     int
+		bits     int
+	)
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			if err == io.EOF {
+				return nil, errors.New("no data chunk")
+			}
+			return nil, fmt.Errorf("reading chunk: %w", err)
+		}
+		id, size := string(hdr[0:4]), int64(binary.LittleEndian.Uint32(hdr[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("fmt chunk too short (%d bytes)", size)
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, fmt.Errorf("reading fmt chunk: %w", err)
+			}
+			tag = binary.LittleEndian.Uint16(body[0:2])
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			rate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bits = int(binary.LittleEndian.Uint16(body[14:16]))
+			if tag == wavExtensible && size >= 26 {
+				tag = binary.LittleEndian.Uint16(body[24:26])
+			}
+			haveFmt = true
+
+		case "data":
+			if !haveFmt {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+			convert, err := wavSampleReader(tag, bits)
+			if err != nil {
+				return nil, err
+			}
+			// Files cut short keep whatever whole samples made it.
+			data, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil {
+				return nil, fmt.Errorf("reading data chunk: %w", err)
+			}
+			width := bits / 8
+			samples := make([]float32, len(data)/width)
+			for i := range samples {
+				samples[i] = convert(data[i*width:])
+			}
+			return interleaved(samples, rate, channels)
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return nil, fmt.Errorf("skipping %q chunk: %w", id, err)
+			}
+		}
+		// Chunks are padded to an even length.
+		if size%2 == 1 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+	}
+}
+
+// wavSampleReader returns a function converting one sample of the given
+// format to a float from -1 to 1.
+func wavSampleReader(tag uint16, bits int) (func(b []byte) float32, error) {
+	switch {
+	case tag == wavPCM && bits == 8:
+		return func(b []byte) float32 { return (float32(b[0]) - 128) / 128 }, nil
+	case tag == wavPCM && bits == 16:
+		return func(b []byte) float32 {
+			return float32(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		}, nil
+	case tag == wavPCM && bits == 24:
+		return func(b []byte) float32 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float32(v) / (1 << 23)
+		}, nil
+	case tag == wavPCM && bits == 32:
+		return func(b []byte) float32 {
+			return float32(float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31))
+		}, nil
+	case tag == wavFloat && bits == 32:
+		return func(b []byte) float32 { return math.Float32frombits(binary.LittleEndian.Uint32(b)) }, nil
+	}
+	return nil, fmt.Errorf("unsupported WAV encoding (format %d, %d bits)", tag, bits)
+}
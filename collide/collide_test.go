@@ -0,0 +1,219 @@
+package collide
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Shape
+		want bool
+	}{
+		{"circles apart", Circ(pixel.V(0, 0), 1), Circ(pixel.V(3, 0), 1), false},
+		{"circles overlapping", Circ(pixel.V(0, 0), 2), Circ(pixel.V(3, 0), 2), true},
+		{"circles touching", Circ(pixel.V(0, 0), 1), Circ(pixel.V(2, 0), 1), false},
+		{"boxes overlapping", BoxAt(pixel.V(0, 0), pixel.V(4, 4)), BoxAt(pixel.V(3, 3), pixel.V(4, 4)), true},
+		{"boxes sharing an edge", Box(pixel.R(0, 0, 2, 2)), Box(pixel.R(2, 0, 4, 2)), false},
+		{"boxes sharing a corner", Box(pixel.R(0, 0, 2, 2)), Box(pixel.R(2, 2, 4, 4)), false},
+		{"box inside box", Box(pixel.R(0, 0, 10, 10)), Box(pixel.R(4, 4, 5, 5)), true},
+		{"circle beside box", Circ(pixel.V(-1, 1), 1.5), Box(pixel.R(0, 0, 2, 2)), true},
+		{"circle off box corner", Circ(pixel.V(-1, -1), 1.2), Box(pixel.R(0, 0, 2, 2)), false},
+		{"circle inside box", Circ(pixel.V(1, 1), 0.1), Box(pixel.R(0, 0, 2, 2)), true},
+		{"box then circle", Box(pixel.R(0, 0, 2, 2)), Circ(pixel.V(3, 1), 1.5), true},
+		{"outside shape by bounds", bounded(pixel.R(0, 0, 2, 2)), Box(pixel.R(1, 1, 3, 3)), true},
+		{"outside shape apart", bounded(pixel.R(0, 0, 2, 2)), Circ(pixel.V(5, 5), 1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Overlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("Overlap(a, b) = %v, want %v", got, tt.want)
+			}
+			if got := Overlap(tt.b, tt.a); got != tt.want {
+				t.Errorf("Overlap(b, a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// bounded is a shape from outside the package, known only by its bounds.
+type bounded pixel.Rect
+
+func (b bounded) Bounds() pixel.Rect      { return pixel.Rect(b) }
+func (b bounded) Moved(d pixel.Vec) Shape { return bounded(pixel.Rect(b).Moved(d)) }
+
+// leftStrip is an 8x4 picture whose only solid pixels are its two
+// leftmost columns.
+func leftStrip() *pixel.PictureData {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			pic.Pix[pic.Index(pixel.V(float64(x), float64(y)))].A = 255
+		}
+		// Faint pixels don't count.
+		pic.Pix[pic.Index(pixel.V(6, float64(y)))].A = alphaSolid - 1
+	}
+	return pic
+}
+
+func TestMask(t *testing.T) {
+	m := AlphaMask(leftStrip(), pixel.R(0, 0, 8, 4))
+	if m.Empty() {
+		t.Fatal("mask is empty")
+	}
+
+	// Centred on the origin the strip covers x from -4 to -2; flipped, it
+	// covers 2 to 4.
+	dot := func(x float64) Shape { return BoxAt(pixel.V(x, 0), pixel.V(1, 1)) }
+	tests := []struct {
+		name   string
+		flip   bool
+		bounds pixel.Rect
+		hit    []float64
+		miss   []float64
+	}{
+		{"facing left", false, pixel.R(-4, -2, -2, 2), []float64{-3.5, -2.5}, []float64{-1, 2.5, 3.5}},
+		{"flipped", true, pixel.R(2, -2, 4, 2), []float64{2.5, 3.5}, []float64{-3.5, -2.5, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed := m.At(pixel.ZV, tt.flip)
+			if b := placed.Bounds(); b != tt.bounds {
+				t.Errorf("bounds %v, want %v", b, tt.bounds)
+			}
+			for _, x := range tt.hit {
+				if !Overlap(placed, dot(x)) {
+					t.Errorf("misses a dot at x=%v", x)
+				}
+			}
+			for _, x := range tt.miss {
+				if Overlap(placed, dot(x)) {
+					t.Errorf("hits a dot at x=%v", x)
+				}
+			}
+		})
+	}
+
+	moved := m.At(pixel.ZV, false).Moved(pixel.V(10, 0))
+	if !Overlap(moved, dot(6.5)) || Overlap(moved, dot(-3.5)) {
+		t.Error("moved mask didn't move")
+	}
+}
+
+func TestMaskAgainstMask(t *testing.T) {
+	m := AlphaMask(leftStrip(), pixel.R(0, 0, 8, 4))
+	// Two sprites side by side, facing apart, have their solid strips at
+	// the far ends; facing each other, the strips meet in the middle.
+	apart := [2]Shape{m.At(pixel.V(0, 0), false), m.At(pixel.V(7, 0), true)}
+	facing := [2]Shape{m.At(pixel.V(0, 0), true), m.At(pixel.V(7, 0), false)}
+	if Overlap(apart[0], apart[1]) {
+		t.Error("masks facing apart overlap")
+	}
+	if !Overlap(facing[0], facing[1]) {
+		t.Error("masks facing each other don't overlap")
+	}
+}
+
+func TestEmptyMask(t *testing.T) {
+	m := AlphaMask(pixel.MakePictureData(pixel.R(0, 0, 4, 4)), pixel.R(0, 0, 4, 4))
+	if !m.Empty() {
+		t.Fatal("clear picture made a solid mask")
+	}
+	if Overlap(m.At(pixel.ZV, false), BoxAt(pixel.ZV, pixel.V(10, 10))) {
+		t.Error("empty mask overlaps")
+	}
+}
+
+func TestSpaceEvents(t *testing.T) {
+	const (
+		player Layer = 1 << iota
+		pickup
+	)
+	at := func(x float64) Body {
+		return Body{Shape: BoxAt(pixel.V(x, 0), pixel.V(2, 2)), Layer: pickup}
+	}
+	hero := func(x float64) Body {
+		return Body{Shape: Circ(pixel.V(x, 0), 1.5), Layer: player, Mask: pickup}
+	}
+
+	s := NewSpace(4)
+	s.Set(10, at(0))
+	s.Set(11, at(8))
+	s.Set(12, at(40))
+
+	steps := []struct {
+		name   string
+		do     func()
+		events []Event
+	}{
+		{"hero away", func() { s.Set(1, hero(20)) }, nil},
+		{"onto first", func() { s.Set(1, hero(0.5)) }, []Event{{Begin, 1, 10}}},
+		{"still there", func() {}, nil},
+		{"between", func() { s.Set(1, hero(4)) }, []Event{{End, 1, 10}}},
+		{"across both", func() { s.Set(1, Body{Shape: Box(pixel.R(-2, -1, 9, 1)), Layer: player, Mask: pickup}) }, []Event{
+			{Begin, 1, 10}, {Begin, 1, 11},
+		}},
+		{"onto second", func() { s.Set(1, hero(8)) }, []Event{{End, 1, 10}}},
+		{"pickup removed", func() { s.Remove(11) }, []Event{{End, 1, 11}}},
+		{"far pickup", func() { s.Set(1, hero(40)) }, []Event{{Begin, 1, 12}}},
+		{"hero stops looking", func() { s.Set(1, Body{Shape: Circ(pixel.V(40, 0), 1.5), Layer: player}) }, []Event{{End, 1, 12}}},
+	}
+	for _, st := range steps {
+		st.do()
+		if got := s.Update(); !reflect.DeepEqual(got, st.events) {
+			t.Errorf("%s: events %v, want %v", st.name, got, st.events)
+		}
+	}
+	if s.Len() != 3 {
+		t.Errorf("%d bodies, want 3", s.Len())
+	}
+}
+
+func TestSpaceBothSides(t *testing.T) {
+	// Two bodies that each look for the other both hear about it, ends
+	// before begins and in ID order.
+	s := NewSpace(8)
+	b := func(x float64) Body { return Body{Shape: Circ(pixel.V(x, 0), 1), Layer: 1, Mask: 1} }
+	s.Set(2, b(0))
+	s.Set(1, b(1))
+	s.Set(3, b(20))
+	if got, want := s.Update(), []Event{{Begin, 1, 2}, {Begin, 2, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events %v, want %v", got, want)
+	}
+	if !s.Touching(1, 2) || !s.Touching(2, 1) || s.Touching(1, 3) {
+		t.Error("Touching disagrees with the events")
+	}
+
+	s.Set(3, b(1.5))
+	s.Set(2, b(-5))
+	want := []Event{{End, 1, 2}, {End, 2, 1}, {Begin, 1, 3}, {Begin, 3, 1}}
+	if got := s.Update(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events %v, want %v", got, want)
+	}
+}
+
+func TestSpaceQuery(t *testing.T) {
+	s := NewSpace(4)
+	for i := 0; i < 10; i++ {
+		s.Set(ID(9-i), Body{Shape: BoxAt(pixel.V(float64(i)*3, 0), pixel.V(2, 2)), Layer: Layer(1 << (i % 2))})
+	}
+	tests := []struct {
+		name string
+		sh   Shape
+		mask Layer
+		want []ID
+	}{
+		{"all layers", Box(pixel.R(-1, -1, 10, 1)), 3, []ID{6, 7, 8, 9}},
+		{"one layer", Box(pixel.R(-1, -1, 10, 1)), 1, []ID{7, 9}},
+		{"no layers", Box(pixel.R(-1, -1, 10, 1)), 0, nil},
+		{"nothing there", Circ(pixel.V(100, 100), 5), 3, nil},
+	}
+	for _, tt := range tests {
+		if got := s.Query(tt.sh, tt.mask); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: found %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
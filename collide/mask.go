@@ -0,0 +1,122 @@
+package collide
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// alphaSolid is the least alpha a pixel needs to count as solid, so soft
+// anti-aliased edges don't reach further than they look.
+const alphaSolid = 128
+
+// Mask is the solid pixels of an image, one bit per pixel, trimmed to the
+// smallest box that holds them. It is placed in the world with At.
+type Mask struct {
+	w, h   int
+	solid  []bool    // row by row, from the bottom
+	offset pixel.Vec // trimmed area's corner within the source rectangle
+	full   pixel.Vec // size of the source rectangle
+}
+
+// AlphaMask builds a mask from the pixels of pic inside r, such as one
+// frame of a spritesheet.
+func AlphaMask(pic *pixel.PictureData, r pixel.Rect) *Mask {
+	x0, y0 := int(math.Floor(r.Min.X)), int(math.Floor(r.Min.Y))
+	w, h := int(r.W()), int(r.H())
+	opaque := func(x, y int) bool {
+		at := pixel.V(float64(x0+x), float64(y0+y))
+		return pic.Rect.Contains(at) && pic.Pix[pic.Index(at)].A >= alphaSolid
+	}
+
+	// Trim to the solid pixels.
+	loX, loY, hiX, hiY := w, h, -1, -1
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if opaque(x, y) {
+				loX, loY = min(loX, x), min(loY, y)
+				hiX, hiY = max(hiX, x), max(hiY, y)
+			}
+		}
+	}
+
+	m := &Mask{full: pixel.V(float64(w), float64(h))}
+	if hiX < 0 {
+		return m
+	}
+	m.w, m.h = hiX-loX+1, hiY-loY+1
+	m.offset = pixel.V(float64(loX), float64(loY))
+	m.solid = make([]bool, m.w*m.h)
+	for y := 0; y < m.h; y++ {
+		for x := 0; x < m.w; x++ {
+			m.solid[y*m.w+x] = opaque(loX+x, loY+y)
+		}
+	}
+	return m
+}
+
+// Empty reports whether the mask has no solid pixels at all.
+func (m *Mask) Empty() bool { return m.w == 0 }
+
+// At places the mask in the world with the centre of its source rectangle
+// at c, mirrored left to right if flip is set, as for a sprite drawn facing
+// the other way.
+func (m *Mask) At(c pixel.Vec, flip bool) Shape {
+	corner := c.Sub(m.full.Scaled(0.5))
+	ox := m.offset.X
+	if flip {
+		ox = m.full.X - m.offset.X - float64(m.w)
+	}
+	return Placed{m: m, min: corner.Add(pixel.V(ox, m.offset.Y)), flip: flip}
+}
+
+// Placed is a mask at a spot in the world.
+type Placed struct {
+	m    *Mask
+	min  pixel.Vec // bottom-left corner of the trimmed mask
+	flip bool
+}
+
+func (p Placed) Bounds() pixel.Rect {
+	return pixel.Rect{Min: p.min, Max: p.min.Add(pixel.V(float64(p.m.w), float64(p.m.h)))}
+}
+
+func (p Placed) Moved(d pixel.Vec) Shape {
+	p.min = p.min.Add(d)
+	return p
+}
+
+// solidAt reports whether the pixel at column x, row y of the placed mask
+// is solid, counting columns as they appear in the world.
+func (p Placed) solidAt(x, y int) bool {
+	if x < 0 || y < 0 || x >= p.m.w || y >= p.m.h {
+		return false
+	}
+	if p.flip {
+		x = p.m.w - 1 - x
+	}
+	return p.m.solid[y*p.m.w+x]
+}
+
+// hits reports whether any solid pixel overlaps o, testing each pixel as a
+// one-pixel box. Only pixels under o's bounds are looked at.
+func (p Placed) hits(o Shape) bool {
+	b := p.Bounds().Intersect(o.Bounds())
+	if b.W() <= 0 || b.H() <= 0 {
+		return false
+	}
+	x0, y0 := int(math.Floor(b.Min.X-p.min.X)), int(math.Floor(b.Min.Y-p.min.Y))
+	x1, y1 := int(math.Ceil(b.Max.X-p.min.X)), int(math.Ceil(b.Max.Y-p.min.Y))
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if !p.solidAt(x, y) {
+				continue
+			}
+			px := p.min.Add(pixel.V(float64(x), float64(y)))
+			if Overlap(Box{Min: px, Max: px.Add(pixel.V(1, 1))}, o) {
+				return true
+			}
+		}
+	}
+	return false
+}
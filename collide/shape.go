@@ -0,0 +1,88 @@
+// Package collide finds which shapes overlap. Shapes are circles,
+// axis-aligned boxes and pixel masks cut from a sprite's alpha; a Space
+// keeps track of many of them in a spatial hash so only nearby pairs are
+// tested, and reports when pairs start and stop touching.
+package collide
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Shape is an area that can collide.
+type Shape interface {
+	// Bounds returns the smallest box that holds the shape.
+	Bounds() pixel.Rect
+	// Moved returns the shape shifted by d.
+	Moved(d pixel.Vec) Shape
+}
+
+// Circle is a disc of radius R around C.
+type Circle struct {
+	C pixel.Vec
+	R float64
+}
+
+// Circ returns a circle of radius r around c.
+func Circ(c pixel.Vec, r float64) Circle { return Circle{C: c, R: r} }
+
+func (c Circle) Bounds() pixel.Rect {
+	return pixel.R(c.C.X-c.R, c.C.Y-c.R, c.C.X+c.R, c.C.Y+c.R)
+}
+
+func (c Circle) Moved(d pixel.Vec) Shape { return Circle{C: c.C.Add(d), R: c.R} }
+
+// Box is an axis-aligned rectangle.
+type Box pixel.Rect
+
+// BoxAt returns a box of size centred on c.
+func BoxAt(c, size pixel.Vec) Box {
+	h := size.Scaled(0.5)
+	return Box{Min: c.Sub(h), Max: c.Add(h)}
+}
+
+func (b Box) Bounds() pixel.Rect { return pixel.Rect(b) }
+
+func (b Box) Moved(d pixel.Vec) Shape { return Box(pixel.Rect(b).Moved(d)) }
+
+// Overlap reports whether a and b share any area. Shapes that only touch
+// along an edge or at a point don't overlap.
+func Overlap(a, b Shape) bool {
+	if p, ok := a.(Placed); ok {
+		return p.hits(b)
+	}
+	if p, ok := b.(Placed); ok {
+		return p.hits(a)
+	}
+	switch a := a.(type) {
+	case Circle:
+		switch b := b.(type) {
+		case Circle:
+			r := a.R + b.R
+			return a.C.Sub(b.C).SqLen() < r*r
+		case Box:
+			return circleBox(a, b)
+		}
+	case Box:
+		switch b := b.(type) {
+		case Circle:
+			return circleBox(b, a)
+		case Box:
+			return a.Min.X < b.Max.X && b.Min.X < a.Max.X &&
+				a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+		}
+	}
+	// Shapes from outside the package are tested by their bounds.
+	return Overlap(Box(a.Bounds()), Box(b.Bounds()))
+}
+
+// circleBox tests a circle against the nearest point of a box to its
+// centre.
+func circleBox(c Circle, b Box) bool {
+	near := pixel.V(
+		math.Max(b.Min.X, math.Min(c.C.X, b.Max.X)),
+		math.Max(b.Min.Y, math.Min(c.C.Y, b.Max.Y)),
+	)
+	return near.Sub(c.C).SqLen() < c.R*c.R
+}
@@ -0,0 +1,207 @@
+package collide
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// ID names a body in a Space. Callers pick their own IDs.
+type ID int
+
+// Layer is a set of bits saying what kind of thing a body is, such as the
+// player or a pickup, so bodies can choose what they collide with.
+type Layer uint32
+
+// Body is a shape in a Space.
+type Body struct {
+	Shape Shape
+	Layer Layer // what the body is
+	Mask  Layer // the layers it reports touching; 0 for none
+}
+
+// EventKind says whether two bodies started or stopped touching.
+type EventKind int
+
+const (
+	Begin EventKind = iota
+	End
+)
+
+func (k EventKind) String() string {
+	if k == End {
+		return "end"
+	}
+	return "begin"
+}
+
+// Event reports that body A, whose mask covers B's layer, started or
+// stopped touching body B.
+type Event struct {
+	Kind EventKind
+	A, B ID
+}
+
+type cell struct{ x, y int }
+
+type pair struct{ a, b ID }
+
+type entry struct {
+	Body
+	lo, hi cell // range of cells the shape's bounds cover
+}
+
+// Space holds bodies in a spatial hash of square cells, so a body is only
+// tested against those sharing a cell with it. Cells about the size of the
+// bodies work best. Results are always in ID order, so a game stepped with
+// the same inputs sees the same events.
+type Space struct {
+	size     float64
+	bodies   map[ID]*entry
+	cells    map[cell]map[ID]struct{}
+	touching map[pair]bool
+}
+
+// NewSpace returns an empty space hashed into cells of cellSize.
+func NewSpace(cellSize float64) *Space {
+	return &Space{
+		size:     cellSize,
+		bodies:   make(map[ID]*entry),
+		cells:    make(map[cell]map[ID]struct{}),
+		touching: make(map[pair]bool),
+	}
+}
+
+// Set adds the body called id, or replaces it if there already is one, as
+// when it moves.
+func (s *Space) Set(id ID, b Body) {
+	s.Remove(id)
+	e := &entry{Body: b}
+	e.lo, e.hi = s.span(b.Shape)
+	s.bodies[id] = e
+	e.each(func(c cell) {
+		if s.cells[c] == nil {
+			s.cells[c] = make(map[ID]struct{})
+		}
+		s.cells[c][id] = struct{}{}
+	})
+}
+
+// Remove takes the body called id out of the space. Anything it was
+// touching gets an End event at the next Update.
+func (s *Space) Remove(id ID) {
+	e, ok := s.bodies[id]
+	if !ok {
+		return
+	}
+	e.each(func(c cell) {
+		delete(s.cells[c], id)
+		if len(s.cells[c]) == 0 {
+			delete(s.cells, c)
+		}
+	})
+	delete(s.bodies, id)
+}
+
+// Get returns the body called id.
+func (s *Space) Get(id ID) (Body, bool) {
+	e, ok := s.bodies[id]
+	if !ok {
+		return Body{}, false
+	}
+	return e.Body, true
+}
+
+// Len returns how many bodies are in the space.
+func (s *Space) Len() int { return len(s.bodies) }
+
+// Query returns the bodies whose layer is in mask and that overlap sh, in
+// ID order.
+func (s *Space) Query(sh Shape, mask Layer) []ID {
+	lo, hi := s.span(sh)
+	return s.query(sh, mask, lo, hi, func(ID) bool { return true })
+}
+
+// Update compares which bodies touch now with the last Update and returns
+// an event for each pair that started or stopped. A pair is reported from
+// the side of each body whose mask covers the other's layer. Ends come
+// before begins, each in ID order.
+func (s *Space) Update() []Event {
+	now := make(map[pair]bool)
+	for _, id := range s.ids() {
+		e := s.bodies[id]
+		if e.Mask == 0 {
+			continue
+		}
+		others := s.query(e.Shape, e.Mask, e.lo, e.hi, func(o ID) bool { return o != id })
+		for _, o := range others {
+			now[pair{id, o}] = true
+		}
+	}
+
+	var events []Event
+	for p := range s.touching {
+		if !now[p] {
+			events = append(events, Event{End, p.a, p.b})
+		}
+	}
+	for p := range now {
+		if !s.touching[p] {
+			events = append(events, Event{Begin, p.a, p.b})
+		}
+	}
+	s.touching = now
+	slices.SortFunc(events, func(x, y Event) int {
+		return cmp.Or(cmp.Compare(y.Kind, x.Kind), cmp.Compare(x.A, y.A), cmp.Compare(x.B, y.B))
+	})
+	return events
+}
+
+// Touching reports whether a's mask covered b and they overlapped at the
+// last Update.
+func (s *Space) Touching(a, b ID) bool { return s.touching[pair{a, b}] }
+
+func (s *Space) query(sh Shape, mask Layer, lo, hi cell, keep func(ID) bool) []ID {
+	seen := make(map[ID]bool)
+	var found []ID
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			for id := range s.cells[cell{x, y}] {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				e := s.bodies[id]
+				if e.Layer&mask != 0 && keep(id) && Overlap(sh, e.Shape) {
+					found = append(found, id)
+				}
+			}
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+// span returns the range of cells sh's bounds cover.
+func (s *Space) span(sh Shape) (lo, hi cell) {
+	b := sh.Bounds()
+	at := func(v float64) int { return int(math.Floor(v / s.size)) }
+	return cell{at(b.Min.X), at(b.Min.Y)}, cell{at(b.Max.X), at(b.Max.Y)}
+}
+
+func (s *Space) ids() []ID {
+	ids := make([]ID, 0, len(s.bodies))
+	for id := range s.bodies {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (e *entry) each(f func(cell)) {
+	for x := e.lo.x; x <= e.hi.x; x++ {
+		for y := e.lo.y; y <= e.hi.y; y++ {
+			f(cell{x, y})
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"unicorn-toots/game"
+	"unicorn-toots/replay"
+)
+
+// validateCmd loads everything the game would and fails if any of it is
+// missing or broken.
+func validateCmd(args []string) error {
+	fs := newFlagSet("validate", "")
+	var src sources
+	src.register(fs)
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("validate: unexpected argument %q", fs.Arg(0))
+	}
+
+	problems := 0
+	if _, err := src.loadConfig(); err != nil {
+		fmt.Println("config:", err)
+		problems++
+	}
+	a := game.LoadAssets(src.manager())
+	for _, err := range a.Problems {
+		fmt.Println("assets:", err)
+	}
+	problems += len(a.Problems)
+
+	fmt.Printf("%d words, %d word images, %d spoken words, %d spoken letters, %d arenas, %d sounds\n",
+		len(a.Words), len(a.WordImages), len(a.WordSounds), len(a.LetterSounds), len(a.Arenas), len(a.Sounds))
+	if problems > 0 {
+		return fmt.Errorf("validate: %d problem(s) found", problems)
+	}
+	fmt.Println("ok")
+	return nil
+}
+
+// listCmd prints game data, one item per line.
+func listCmd(args []string) error {
+	fs := newFlagSet("list", "words")
+	var src sources
+	src.register(fs)
+	fs.Parse(args)
+	switch {
+	case fs.NArg() == 0:
+		return errors.New(`list: nothing to list; try "list words"`)
+	case fs.Arg(0) != "words":
+		return fmt.Errorf("list: can't list %q, only words", fs.Arg(0))
+	case fs.NArg() > 1:
+		return fmt.Errorf("list: unexpected argument %q", fs.Arg(1))
+	}
+
+	for _, w := range loadAssets(src.manager()).Words {
+		fmt.Println(w)
+	}
+	return nil
+}
+
+// verifyCmd plays a recording back without a window and reports the first
+// step where the game state no longer matches it.
+func verifyCmd(args []string) error {
+	fs := newFlagSet("verify", "REPLAY")
+	var src sources
+	src.register(fs)
+	fs.Parse(args)
+	switch {
+	case fs.NArg() == 0:
+		return errors.New("verify: no replay file given")
+	case fs.NArg() > 1:
+		return fmt.Errorf("verify: unexpected argument %q", fs.Arg(1))
+	}
+
+	conf, err := src.loadConfig()
+	if err != nil {
+		return err
+	}
+	rec, err := replay.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	g := game.New(loadAssets(src.manager()), conf, rec.Seed)
+	g.Start(game.Mode(rec.Start))
+	step := 1 / float64(rec.TickRate)
+	for p := rec.Player(); !p.Done(); {
+		g.Update(step, p.Poll())
+		if err := rec.Verify(p.Tick(), g.Checksum()); err != nil {
+			return err
+		}
+	}
+	fmt.Println("replay ok")
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package config loads the gameplay tunables from a versioned JSON file, so
+// the game can be adjusted for different age groups without rebuilding.
+//
+// Every field is optional; anything left out keeps its default. A minimal
+// file looks like:
+//
+//	{
+//	  "version": 1,
+//	  "move_speed": 150,
+//	  "floatiness": 0.5,
+//	  "try_again_delay": 3
+//	}
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Version is the config file format this build understands.
+const Version = 1
+
+// Config holds the gameplay tunables. Sizes are in canvas pixels, where one
+// pixel of art is one canvas pixel, and times in seconds.
+type Config struct {
+	Version int `json:"version"`
+
+	WindowWidth  int `json:"window_width"`  // starting window size, in screen pixels
+	WindowHeight int `json:"window_height"` // starting window size, in screen pixels
+
+	// The game is drawn on a low-resolution canvas that is scaled up to the
+	// window by a whole number. With letterbox set the canvas is always
+	// exactly this size and bars fill the rest of the window; otherwise this
+	// is the smallest it gets and it grows to fill the window.
+	CanvasWidth  int  `json:"canvas_width"`
+	CanvasHeight int  `json:"canvas_height"`
+	Letterbox    bool `json:"letterbox"`
+
+	// The world the unicorn roams can be larger than the canvas, which then
+	// scrolls to follow it. A world smaller than the canvas, such as the
+	// default of 0, is grown to fill it. Arenas bring their own size.
+	WorldWidth  int `json:"world_width"`
+	WorldHeight int `json:"world_height"`
+
+	CameraDeadZone float64 `json:"camera_dead_zone"` // fraction of the view the unicorn roams before it scrolls
+	CameraLag      float64 `json:"camera_lag"`       // seconds the camera takes to catch up
+
+	UnicornSize   float64 `json:"unicorn_size"`   // keeps the sprite on screen
+	MoveSpeed     float64 `json:"move_speed"`     // top speed, in pixels per second
+	FrameDuration float64 `json:"frame_duration"` // seconds per walk frame
+
+	// Floatiness is how much the unicorn drifts, from 0 for starting and
+	// stopping on the spot to 1 for a second's glide up to speed and back
+	// to rest. A little float is gentler to steer for small hands.
+	Floatiness float64 `json:"floatiness"`
+
+	LetterSize   float64 `json:"letter_size"` // collision size of a letter
+	GemSize      float64 `json:"gem_size"`    // collision size of a gem
+	GemsPerBatch int     `json:"gems_per_batch"`
+
+	BgScale    int     `json:"bg_scale"`    // pixels per noise sample (lower = more detail but slower)
+	NoiseScale float64 `json:"noise_scale"` // scale of the noise pattern
+
+	TryAgainDelay     float64 `json:"try_again_delay"`     // how long "Try Again!" shows
+	WordCompleteDelay float64 `json:"word_complete_delay"` // how long a finished word is celebrated
+
+	EffectsVolume float64 `json:"effects_volume"` // from 0 for silent to 1 for full volume
+	SpeechVolume  float64 `json:"speech_volume"`  // loudness of spoken words and letters, likewise
+}
+
+// Default returns the tunables the game shipped with.
+func Default() Config {
+	return Config{
+		Version: Version,
+
+		WindowWidth:  800,
+		WindowHeight: 600,
+
+		CanvasWidth:  400,
+		CanvasHeight: 300,
+		Letterbox:    true,
+
+		CameraDeadZone: 0.3,
+		CameraLag:      0.2,
+
+		UnicornSize:   32,
+		MoveSpeed:     100,
+		FrameDuration: 0.15,
+		Floatiness:    0.2,
+
+		LetterSize:   15,
+		GemSize:      15,
+		GemsPerBatch: 5,
+
+		BgScale:    4,
+		NoiseScale: 0.02,
+
+		TryAgainDelay:     2,
+		WordCompleteDelay: 3,
+
+		EffectsVolume: 1,
+		SpeechVolume:  1,
+	}
+}
+
+// Load reads the config file at path, filling in defaults for missing fields.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates a config file's contents.
+func Parse(data []byte) (Config, error) {
+	cfg := Default()
+	cfg.Version = 0
+	if err := decode(data, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	switch {
+	case cfg.Version == 0:
+		return Config{}, fmt.Errorf(`missing "version" (this build reads version %d)`, Version)
+	case cfg.Version != Version:
+		return Config{}, fmt.Errorf("version %d isn't one this build understands (%d)", cfg.Version, Version)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// decode reads data over cfg, turning JSON errors into ones that point at
+// the offending line or field.
+func decode(data []byte, cfg *Config) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(cfg)
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		line := 1 + bytes.Count(data[:syntax.Offset], []byte("\n"))
+		return fmt.Errorf("line %d: %v", line, syntax)
+	case errors.As(err, &typ):
+		return fmt.Errorf("%s must be %s, not %s", typ.Field, kindName(typ.Type.Kind()), typ.Value)
+	}
+	return err
+}
+
+// Validate checks every field is in a usable range, reporting all problems
+// at once.
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.WindowWidth >= 320, "window_width must be at least 320 (got %d)", c.WindowWidth)
+	check(c.WindowHeight >= 240, "window_height must be at least 240 (got %d)", c.WindowHeight)
+	check(c.CanvasWidth >= 160, "canvas_width must be at least 160 (got %d)", c.CanvasWidth)
+	check(c.CanvasHeight >= 120, "canvas_height must be at least 120 (got %d)", c.CanvasHeight)
+	check(c.WorldWidth >= 0, "world_width must not be negative (got %d)", c.WorldWidth)
+	check(c.WorldHeight >= 0, "world_height must not be negative (got %d)", c.WorldHeight)
+	check(c.CameraDeadZone >= 0 && c.CameraDeadZone <= 1, "camera_dead_zone must be between 0 and 1 (got %g)", c.CameraDeadZone)
+	check(c.CameraLag >= 0, "camera_lag must not be negative (got %g)", c.CameraLag)
+	check(c.UnicornSize > 0, "unicorn_size must be positive (got %g)", c.UnicornSize)
+	check(c.MoveSpeed > 0, "move_speed must be positive (got %g)", c.MoveSpeed)
+	check(c.Floatiness >= 0 && c.Floatiness <= 1, "floatiness must be between 0 and 1 (got %g)", c.Floatiness)
+	check(c.FrameDuration > 0, "frame_duration must be positive (got %g)", c.FrameDuration)
+	check(c.LetterSize > 0, "letter_size must be positive (got %g)", c.LetterSize)
+	check(c.GemSize > 0, "gem_size must be positive (got %g)", c.GemSize)
+	check(c.GemsPerBatch >= 1 && c.GemsPerBatch <= 50, "gems_per_batch must be between 1 and 50 (got %d)", c.GemsPerBatch)
+	check(c.BgScale >= 1, "bg_scale must be at least 1 (got %d)", c.BgScale)
+	check(c.NoiseScale > 0, "noise_scale must be positive (got %g)", c.NoiseScale)
+	check(c.TryAgainDelay >= 0, "try_again_delay must not be negative (got %g)", c.TryAgainDelay)
+	check(c.WordCompleteDelay >= 0, "word_complete_delay must not be negative (got %g)", c.WordCompleteDelay)
+	check(c.EffectsVolume >= 0 && c.EffectsVolume <= 1, "effects_volume must be between 0 and 1 (got %g)", c.EffectsVolume)
+	check(c.SpeechVolume >= 0 && c.SpeechVolume <= 1, "speech_volume must be between 0 and 1 (got %g)", c.SpeechVolume)
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// kindName describes a field's Go kind in the terms a config file uses.
+func kindName(k reflect.Kind) string {
+	switch k {
+	case reflect.Int, reflect.Int64:
+		return "a whole number"
+	case reflect.Float64:
+		return "a number"
+	}
+	return k.String()
+}
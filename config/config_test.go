@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want func(c *Config) // adjusts Default to the expected result
+	}{
+		{"defaults", `{"version": 1}`, func(c *Config) {}},
+		{"overrides", `{"version": 1, "move_speed": 150, "floatiness": 0.5, "letterbox": false}`, func(c *Config) {
+			c.MoveSpeed = 150
+			c.Floatiness = 0.5
+			c.Letterbox = false
+		}},
+		{"volumes", `{"version": 1, "effects_volume": 0, "speech_volume": 0.25}`, func(c *Config) {
+			c.EffectsVolume = 0
+			c.SpeechVolume = 0.25
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Default()
+			tt.want(&want)
+			if got != want {
+				t.Errorf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{"no version", `{"move_speed": 150}`, []string{`missing "version"`}},
+		{"newer version", `{"version": 2}`, []string{"version 2 isn't one this build understands"}},
+		{"negative version", `{"version": -1}`, []string{"version -1 isn't one"}},
+		{"syntax", "{\n\"version\": 1,\n}", []string{"line 3"}},
+		{"wrong type", `{"version": 1, "gems_per_batch": 1.5}`, []string{"gems_per_batch must be a whole number"}},
+		{"unknown field", `{"version": 1, "speed": 1}`, []string{`unknown field "speed"`}},
+		{"invalid", `{"version": 1, "move_speed": -2}`, []string{"move_speed must be positive"}},
+		{"several problems", `{"version": 1, "floatiness": 2, "effects_volume": -1, "canvas_width": 10}`, []string{
+			"floatiness must be between 0 and 1 (got 2)",
+			"effects_volume must be between 0 and 1 (got -1)",
+			"canvas_width must be at least 160 (got 10)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.file))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("error %q, want it to mention %q", err, w)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(c *Config)
+		want string // empty for a valid config
+	}{
+		{"default", func(c *Config) {}, ""},
+		{"silent", func(c *Config) { c.EffectsVolume, c.SpeechVolume = 0, 0 }, ""},
+		{"no delays", func(c *Config) { c.TryAgainDelay, c.WordCompleteDelay = 0, 0 }, ""},
+		{"small window", func(c *Config) { c.WindowWidth = 100 }, "window_width"},
+		{"negative world", func(c *Config) { c.WorldHeight = -1 }, "world_height"},
+		{"dead zone", func(c *Config) { c.CameraDeadZone = 1.5 }, "camera_dead_zone"},
+		{"no frame time", func(c *Config) { c.FrameDuration = 0 }, "frame_duration"},
+		{"too many gems", func(c *Config) { c.GemsPerBatch = 51 }, "gems_per_batch"},
+		{"loud speech", func(c *Config) { c.SpeechVolume = 1.1 }, "speech_volume"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.edit(&c)
+			err := c.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("no error, want one about %s", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("error %q, want it to mention %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/collide"
+	"unicorn-toots/render"
+	"unicorn-toots/tilemap"
+)
+
+// arena is a tilemap playfield. The map is the world, so map and world
+// coordinates are the same. Its tiles never change, so they are drawn into
+// one picture up front.
+type arena struct {
+	m      *tilemap.Map
+	sprite *pixel.Sprite
+	walls  *collide.Space
+}
+
+func newArena(m *tilemap.Map) *arena {
+	img := render.NewImage(m.Bounds())
+	m.Draw(img, pixel.IM)
+	pic := pixel.PictureDataFromImage(img.RGBA())
+	a := &arena{m: m, sprite: pixel.NewSprite(pic, pic.Bounds()), walls: collide.NewSpace(cellSize)}
+	for i, w := range m.Walls() {
+		a.walls.Set(collide.ID(i), collide.Body{Shape: collide.Box(w), Layer: layerWall})
+	}
+	return a
+}
+
+// wallsAt returns the walls sh overlaps.
+func (a *arena) wallsAt(sh collide.Shape) []pixel.Rect {
+	var hit []pixel.Rect
+	for _, id := range a.walls.Query(sh, layerWall) {
+		b, _ := a.walls.Get(id)
+		hit = append(hit, b.Shape.Bounds())
+	}
+	return hit
+}
+
+func (a *arena) draw(t render.Target) {
+	a.sprite.Draw(t, pixel.IM.Moved(a.m.Bounds().Center()))
+}
+
+// pickArena chooses the playfield for a new round, or none if there are no
+// arenas to choose from.
+func (g *Game) pickArena() {
+	g.arena = nil
+	if n := len(g.assets.Arenas); n > 0 {
+		g.arena = newArena(g.assets.Arenas[g.rng.Intn(n)])
+	}
+	g.setWorld(g.worldFor(g.size))
+}
+
+// startPos returns where the unicorn starts a round: the arena's
+// "unicorn" spawn point if it has one, or else the middle of the world.
+func (g *Game) startPos() pixel.Vec {
+	if g.arena != nil {
+		if at := g.arena.m.Spawns("unicorn"); len(at) > 0 {
+			return at[0]
+		}
+	}
+	return g.world.Center()
+}
+
+// spawnPoints returns n of the arena's spawn points of class in a random
+// order, or nil if there aren't enough of them for every pickup.
+func (g *Game) spawnPoints(class string, n int) []pixel.Vec {
+	if g.arena == nil {
+		return nil
+	}
+	at := g.arena.m.Spawns(class)
+	if len(at) < n {
+		return nil
+	}
+	picked := make([]pixel.Vec, n)
+	for i, j := range g.rng.Perm(len(at))[:n] {
+		picked[i] = at[j]
+	}
+	return picked
+}
+
+// open reports whether a pickup of size could sit at p without being inside
+// a wall.
+func (g *Game) open(p pixel.Vec, size float64) bool {
+	return g.arena == nil || len(g.arena.wallsAt(collide.Circ(p, size/2))) == 0
+}
+
+// hitWalls stops the unicorn, on its way from from, at the arena's walls.
+// It moves along one axis at a time, so it slides along a wall it runs into
+// at an angle rather than sticking to it.
+func (g *Game) hitWalls(from pixel.Vec) {
+	if g.arena == nil {
+		return
+	}
+	size := pixel.V(g.cfg.UnicornSize, g.cfg.UnicornSize)
+	half := size.Scaled(0.5)
+	to := g.body.Pos
+
+	p := pixel.V(to.X, from.Y)
+	if to.X != from.X {
+		for _, w := range g.arena.wallsAt(collide.BoxAt(p, size)) {
+			if to.X > from.X {
+				p.X = math.Min(p.X, w.Min.X-half.X)
+			} else {
+				p.X = math.Max(p.X, w.Max.X+half.X)
+			}
+			g.body.Vel.X = 0
+		}
+	}
+	p.Y = to.Y
+	if to.Y != from.Y {
+		for _, w := range g.arena.wallsAt(collide.BoxAt(p, size)) {
+			if to.Y > from.Y {
+				p.Y = math.Min(p.Y, w.Min.Y-half.Y)
+			} else {
+				p.Y = math.Max(p.Y, w.Max.Y+half.Y)
+			}
+			g.body.Vel.Y = 0
+		}
+	}
+	g.body.Pos = p
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+
+	"unicorn-toots/anim"
+	"unicorn-toots/assets"
+	"unicorn-toots/audio"
+)
+
+// LoadAssets loads everything the game needs from m. Nothing here is fatal:
+// files that fail to load are replaced with placeholders or the built-in
+// defaults and listed in Problems for the diagnostics screen.
+func LoadAssets(m *assets.Manager) Assets {
+	var a Assets
+	report := func(err error) {
+		if err == nil {
+			return
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			a.Problems = append(a.Problems, joined.Unwrap()...)
+			return
+		}
+		a.Problems = append(a.Problems, err)
+	}
+
+	var err error
+	a.Unicorn, err = m.Spritesheet("unicorn-sheet.png")
+	if err != nil {
+		report(err)
+		a.Unicorn, _ = anim.Strip(assets.Placeholder(), 32, "walk")
+	}
+
+	a.Gem, err = m.Sprite("gem.png")
+	if err != nil {
+		report(err)
+		a.Gem = assets.PlaceholderSprite()
+	}
+
+	a.Words, err = m.Words("words.txt")
+	if err != nil {
+		report(err)
+		a.Words, err = assets.NewManagerFS(assets.Embedded).Words("words.txt")
+		if err != nil {
+			a.Words = []string{"UNICORN"}
+		}
+	}
+
+	a.WordImages, err = m.WordImages("words")
+	report(err)
+
+	a.WordSounds, err = m.Speech("words")
+	report(err)
+
+	a.LetterSounds, err = m.Speech("letters")
+	report(err)
+
+	a.Arenas, err = m.Arenas("arenas")
+	report(err)
+
+	a.Sounds, err = m.Sounds("sounds")
+	report(err)
+
+	return a
+}
+
+// ApplyReload swaps in word data reloaded by an assets.Watcher. Call it
+// between frames. The word currently being spelled is kept even if it left
+// the list; new words are picked from the updated list. Reloaded recordings
+// only play once the game's sound backend is given the new SoundBank.
+func (g *Game) ApplyReload(r assets.Reload) {
+	if r.Words != nil {
+		g.assets.Words = r.Words
+	}
+	if r.WordImages != nil {
+		g.assets.WordImages = r.WordImages
+	}
+	if r.WordSounds != nil {
+		g.assets.WordSounds = r.WordSounds
+	}
+	if r.Err != nil {
+		g.reloadStatus = fmt.Sprintf("Reload error: %v", r.Err)
+	} else {
+		g.reloadStatus = fmt.Sprintf("Reloaded: %d words, %d images, %d recordings",
+			len(g.assets.Words), len(g.assets.WordImages), len(g.assets.WordSounds))
+	}
+}
+
+// SoundBank returns every sound the game may currently ask to play, as
+// Assets.SoundBank does, including any recordings swapped in by ApplyReload.
+func (g *Game) SoundBank() map[string]*audio.Sound { return g.assets.SoundBank() }
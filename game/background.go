@@ -0,0 +1,186 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Perlin noise implementation
+var permutation = [512]int{}
+
+func initPerlin() {
+	p := [256]int{
+		151, 160, 137, 91, 90, 15, 131, 13, 201, 95, 96, 53, 194, 233, 7, 225,
+		140, 36, 103, 30, 69, 142, 8, 99, 37, 240, 21, 10, 23, 190, 6, 148,
+		247, 120, 234, 75, 0, 26, 197, 62, 94, 252, 219, 203, 117, 35, 11, 32,
+		57, 177, 33, 88, 237, 149, 56, 87, 174, 20, 125, 136, 171, 168, 68, 175,
+		74, 165, 71, 134, 139, 48, 27, 166, 77, 146, 158, 231, 83, 111, 229, 122,
+		60, 211, 133, 230, 220, 105, 92, 41, 55, 46, 245, 40, 244, 102, 143, 54,
+		65, 25, 63, 161, 1, 216, 80, 73, 209, 76, 132, 187, 208, 89, 18, 169,
+		200, 196, 135, 130, 116, 188, 159, 86, 164, 100, 109, 198, 173, 186, 3, 64,
+		52, 217, 226, 250, 124, 123, 5, 202, 38, 147, 118, 126, 255, 82, 85, 212,
+		207, 206, 59, 227, 47, 16, 58, 17, 182, 189, 28, 42, 223, 183, 170, 213,
+		119, 248, 152, 2, 44, 154, 163, 70, 221, 153, 101, 155, 167, 43, 172, 9,
+		129, 22, 39, 253, 19, 98, 108, 110, 79, 113, 224, 232, 178, 185, 112, 104,
+		218, 246, 97, 228, 251, 34, 242, 193, 238, 210, 144, 12, 191, 179, 162, 241,
+		81, 51, 145, 235, 249, 14, 239, 107, 49, 192, 214, 31, 181, 199, 106, 157,
+		184, 84, 204, 176, 115, 121, 50, 45, 127, 4, 150, 254, 138, 236, 205, 93,
+		222, 114, 67, 29, 24, 72, 243, 141, 128, 195, 78, 66, 215, 61, 156, 180,
+	}
+	for i := 0; i < 256; i++ {
+		permutation[i] = p[i]
+		permutation[256+i] = p[i]
+	}
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+func grad(hash int, x, y float64) float64 {
+	h := hash & 3
+	switch h {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	default:
+		return -x - y
+	}
+}
+
+func perlin2D(x, y float64) float64 {
+	X := int(math.Floor(x)) & 255
+	Y := int(math.Floor(y)) & 255
+
+	x -= math.Floor(x)
+	y -= math.Floor(y)
+
+	u := fade(x)
+	v := fade(y)
+
+	A := permutation[X] + Y
+	AA := permutation[A]
+	AB := permutation[A+1]
+	B := permutation[X+1] + Y
+	BA := permutation[B]
+	BB := permutation[B+1]
+
+	return lerp(v,
+		lerp(u, grad(permutation[AA], x, y), grad(permutation[BA], x-1, y)),
+		lerp(u, grad(permutation[AB], x, y-1), grad(permutation[BB], x-1, y-1)),
+	)
+}
+
+// Fractal Brownian Motion for richer noise
+func fbm(x, y float64, octaves int) float64 {
+	value := 0.0
+	amplitude := 1.0 // amplitude between 0.5 and 1.5
+	frequency := 1.0
+	maxValue := 0.0
+
+	for i := 0; i < octaves; i++ {
+		value += amplitude * perlin2D(x*frequency, y*frequency)
+		maxValue += amplitude
+		amplitude *= 0.5
+		frequency *= 2
+	}
+
+	return value / maxValue
+}
+
+type Background struct {
+	img        *image.RGBA
+	sprite     *pixel.Sprite
+	width      int
+	height     int
+	scale      int     // pixels per noise sample (lower = more detail but slower)
+	noiseScale float64 // scale of the noise pattern
+	dirX       float64
+	dirY       float64
+}
+
+func newBackground(rng *rand.Rand, w, h, scale int, noiseScale float64) *Background {
+	initPerlin()
+	img := image.NewRGBA(image.Rect(0, 0, ceilDiv(w, scale), ceilDiv(h, scale)))
+
+	// Random direction for animation
+	randoX := -1.0 + rng.Float64()*(1.0-(-1.0)) // random float between -1 and 1
+	randoY := -1.0 + rng.Float64()*(1.0-(-1.0)) // random float between -1 and 1
+	angle := (randoX + randoY) * math.Pi
+	speed := 0.2 + rng.Float64()*0.2 // speed between 0.2 and 0.4
+
+	return &Background{
+		img:        img,
+		width:      w,
+		height:     h,
+		scale:      scale,
+		noiseScale: noiseScale,
+		dirX:       math.Cos(angle) * speed,
+		dirY:       math.Sin(angle) * speed,
+	}
+}
+
+// resized returns a background covering a w x h window that keeps drifting
+// the same way as bg.
+func (bg *Background) resized(w, h int) *Background {
+	return &Background{
+		img:        image.NewRGBA(image.Rect(0, 0, ceilDiv(w, bg.scale), ceilDiv(h, bg.scale))),
+		width:      w,
+		height:     h,
+		scale:      bg.scale,
+		noiseScale: bg.noiseScale,
+		dirX:       bg.dirX,
+		dirY:       bg.dirY,
+	}
+}
+
+// ceilDiv rounds a/b up so the noise image covers any leftover pixels.
+func ceilDiv(a, b int) int { return (a + b - 1) / b }
+
+func (bg *Background) update(t float64) {
+	w := bg.img.Bounds().Dx()
+	h := bg.img.Bounds().Dy()
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			nx := float64(x) * bg.noiseScale
+			ny := float64(y) * bg.noiseScale
+
+			// Animate by moving through noise space using random direction
+			n := fbm(nx+t*bg.dirX, ny+t*bg.dirY, 4)
+
+			// Map noise from [-1, 1] to [0, 1]
+			n = (n + 1) / 2
+
+			// Create a purple-ish gradient based on noise
+			r := uint8(40 + n*60)
+			g := uint8(20 + n*40)
+			b := uint8(80 + n*100)
+
+			bg.img.SetRGBA(x, y, color.RGBA{r, g, b, 255})
+		}
+	}
+
+	// Convert to pixel picture for drawing
+	pic := pixel.PictureDataFromImage(bg.img)
+	bg.sprite = pixel.NewSprite(pic, pic.Bounds())
+}
+
+// draw renders the last updated noise frame scaled up to fill the background.
+func (bg *Background) draw(t pixel.Target) {
+	if bg.sprite == nil {
+		return
+	}
+	bg.sprite.Draw(t, pixel.IM.Scaled(pixel.ZV, float64(bg.scale)).Moved(pixel.V(float64(bg.width)/2, float64(bg.height)/2)))
+}
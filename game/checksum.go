@@ -0,0 +1,52 @@
+package game
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Checksum hashes the simulated state of the game: the scene stack, the
+// unicorn, and the letters or gems in play. Two runs that took the same
+// inputs from the same seed produce the same checksum.
+func (g *Game) Checksum() uint64 {
+	h := fnv.New64a()
+	num := func(v float64) {
+		binary.Write(h, binary.LittleEndian, math.Float64bits(v))
+	}
+
+	num(g.body.Pos.X)
+	num(g.body.Pos.Y)
+	num(g.body.Vel.X)
+	num(g.body.Vel.Y)
+	fmt.Fprintf(h, "%s:%d:%t:%s:%t;", g.unicorn.Clip(), g.unicorn.Index(), g.facingRight, g.reaction, g.holding)
+	num(g.noiseTime)
+	if g.arena != nil {
+		fmt.Fprintf(h, "%s;", g.arena.m.Name)
+	}
+	for _, s := range g.scenes.scenes {
+		fmt.Fprintf(h, "%T;", s)
+		switch s := s.(type) {
+		case *spellingScene:
+			fmt.Fprintf(h, "%s:%d;", s.word, s.nextLetterIdx)
+			for _, l := range s.letters {
+				fmt.Fprintf(h, "%c%t", l.char, l.collected)
+				num(l.pos.X)
+				num(l.pos.Y)
+			}
+		case *gemScene:
+			fmt.Fprintf(h, "%d;", s.score)
+			for _, gem := range s.gems {
+				fmt.Fprintf(h, "%t", gem.collected)
+				num(gem.pos.X)
+				num(gem.pos.Y)
+			}
+		case *tryAgainScene:
+			num(s.timer)
+		case *wordCompleteScene:
+			num(s.timer)
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/collide"
+)
+
+// Collision layers.
+const (
+	layerUnicorn collide.Layer = 1 << iota
+	layerPickup                // letters and gems
+	layerWall                  // an arena's walls
+)
+
+// unicornID is the unicorn's body in a scene's space. Pickups use their
+// index, so they never clash with it.
+const unicornID collide.ID = -1
+
+// cellSize is the spatial hash cell, about the size of the unicorn.
+const cellSize = 32
+
+// unicornShape returns the area the unicorn collides with: the current
+// frame's "hitbox" slice if the sheet has one, or else the frame's solid
+// pixels, placed just where the frame is drawn.
+func (g *Game) unicornShape() collide.Shape {
+	if r, ok := g.unicorn.Slice("hitbox"); ok {
+		if g.facingRight {
+			r.Min.X, r.Max.X = -r.Max.X, -r.Min.X
+		}
+		return collide.Box(r.Moved(g.body.Pos))
+	}
+	off := g.unicorn.Offset()
+	if g.facingRight {
+		off.X = -off.X
+	}
+	return g.unicornMask().At(g.body.Pos.Add(off).Floor(), g.facingRight)
+}
+
+// unicornMask returns the mask of the unicorn's current frame, building it
+// from the frame's alpha the first time the frame is shown.
+func (g *Game) unicornMask() *collide.Mask {
+	frame := g.unicorn.Frame()
+	m, ok := g.masks[frame]
+	if !ok {
+		m = collide.AlphaMask(g.unicorn.Picture(), frame)
+		g.masks[frame] = m
+	}
+	return m
+}
+
+// pickupBody returns the body of a letter or gem of the given size at pos.
+func pickupBody(pos pixel.Vec, size float64) collide.Body {
+	return collide.Body{Shape: collide.Circ(pos, size/2), Layer: layerPickup}
+}
+
+// touchPickups moves the unicorn to where it is now in space and returns
+// the pickups it has just run into, in ID order.
+func (g *Game) touchPickups(space *collide.Space) []collide.ID {
+	space.Set(unicornID, collide.Body{Shape: g.unicornShape(), Layer: layerUnicorn, Mask: layerPickup})
+	var hit []collide.ID
+	for _, ev := range space.Update() {
+		if ev.Kind == collide.Begin && ev.A == unicornID {
+			hit = append(hit, ev.B)
+		}
+	}
+	return hit
+}
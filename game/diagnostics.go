@@ -0,0 +1,59 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// maxProblemLines is how many load problems fit on the diagnostics screen.
+const maxProblemLines = 14
+
+// diagnosticsScene lists the assets that failed to load, so a grown-up can
+// fix them, before letting play continue with placeholders.
+type diagnosticsScene struct {
+	g *Game
+}
+
+func (d *diagnosticsScene) Enter() {}
+func (d *diagnosticsScene) Exit()  {}
+
+func (d *diagnosticsScene) Update(dt float64, in input.State) {
+	if in.Back || in.Click {
+		d.g.scenes.Pop()
+	}
+}
+
+func (d *diagnosticsScene) Draw(t render.Target) {
+	d.g.drawDim(t)
+	b := d.g.Bounds()
+	d.g.drawCentered(t, "Some files could not be loaded", pixel.V(b.Center().X, b.Max.Y-30), 1, colornames.Orange)
+
+	list := text.New(pixel.V(15, b.Max.Y-60), d.g.atlas)
+	list.Color = colornames.White
+	problems := d.g.assets.Problems
+	for i, err := range problems {
+		if i == maxProblemLines {
+			fmt.Fprintf(list, "...and %d more\n", len(problems)-i)
+			break
+		}
+		fmt.Fprintf(list, "- %s\n", truncate(err.Error(), 52))
+	}
+	list.Draw(t, pixel.IM)
+
+	d.g.drawCentered(t, "Click or press Esc to play anyway", pixel.V(b.Center().X, b.Min.Y+20), 1, colornames.Yellow)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/render"
+)
+
+// button is a clickable labelled rectangle on a menu screen.
+type button struct {
+	rect  pixel.Rect
+	label string
+	color color.Color
+}
+
+func (g *Game) drawButton(t render.Target, b button) {
+	g.imd.Clear()
+	g.imd.Color = b.color
+	g.imd.Push(b.rect.Min, b.rect.Max)
+	g.imd.Rectangle(0)
+	g.imd.Draw(t)
+
+	g.drawCentered(t, b.label, b.rect.Center(), 1, colornames.White)
+}
+
+// drawCentered writes s centered on center at the given scale, snapped to
+// whole canvas pixels so the glyphs stay sharp.
+func (g *Game) drawCentered(t render.Target, s string, center pixel.Vec, scale float64, col color.Color) {
+	txt := text.New(pixel.ZV, g.atlas)
+	txt.Color = col
+	txt.WriteString(s)
+	bounds := txt.Bounds()
+	txt.Draw(t, pixel.IM.Scaled(pixel.ZV, scale).Moved(center.Sub(bounds.Center().Scaled(scale)).Floor()))
+}
+
+// drawUnicorn draws the unicorn sprite facing the way it last moved,
+// interpolated between its last two simulated positions and snapped to whole
+// canvas pixels.
+func (g *Game) drawUnicorn(t render.Target) {
+	pos := pixel.Lerp(g.prevPos, g.body.Pos, g.alpha)
+	m, off := pixel.IM, g.unicorn.Offset()
+	if g.facingRight {
+		m = m.ScaledXY(pixel.ZV, pixel.V(-1, 1))
+		off.X = -off.X
+	}
+	g.sprite.Set(g.unicorn.Picture(), g.unicorn.Frame())
+	g.sprite.Draw(t, m.Moved(pos.Add(off).Floor()))
+}
+
+// drawDim darkens the whole canvas behind an overlay message.
+func (g *Game) drawDim(t render.Target) {
+	g.imd.Clear()
+	g.imd.Color = color.RGBA{0, 0, 0, 150}
+	g.imd.Push(g.Bounds().Min, g.Bounds().Max)
+	g.imd.Rectangle(0)
+	g.imd.Draw(t)
+}
+
+// drawDebug shows the session seed, background drift and last asset reload
+// in the corner.
+func (g *Game) drawDebug(t render.Target) {
+	debugText := text.New(pixel.V(5, 45), g.atlas)
+	debugText.Color = colornames.White
+	fmt.Fprintf(debugText, "Seed: %d\n", g.seed)
+	fmt.Fprintf(debugText, "DirX: %.2f, DirY: %.2f\n", g.bg.dirX, g.bg.dirY)
+	if g.reloadStatus != "" {
+		fmt.Fprint(debugText, truncate(g.reloadStatus, 54))
+	}
+	debugText.Draw(t, pixel.IM)
+}
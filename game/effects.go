@@ -0,0 +1,134 @@
+package game
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/particle"
+	"unicorn-toots/render"
+)
+
+// effects are the particles that decorate play. They are only for show:
+// they draw from their own randomness, so they never change how a seed
+// plays out, and they aren't part of the checksum.
+type effects struct {
+	trail    *particle.System // the rainbow toot, drawn behind the unicorn
+	sparks   *particle.System // pickup sparkles, drawn over the world
+	confetti *particle.System // drawn over overlays, in canvas pixels
+
+	rainbow particle.Emitter
+	sparkle particle.Emitter
+	paper   particle.Emitter
+}
+
+// starFrame is where the sparkle star sits in starPicture.
+var starFrame = pixel.R(0, 0, 5, 5)
+
+func newEffects(seed int64) *effects {
+	rng := rand.New(rand.NewSource(seed))
+	stars := starPicture()
+	fade := func(cs ...color.Color) particle.Gradient {
+		return particle.Colors(append(cs, color.Transparent)...)
+	}
+
+	fx := &effects{
+		trail:    particle.New(256, nil, rng),
+		sparks:   particle.New(128, stars, rng),
+		confetti: particle.New(256, nil, rng),
+
+		rainbow: particle.Emitter{
+			Rate:    60,
+			Life:    particle.Range{Min: 0.5, Max: 0.8},
+			Speed:   particle.Range{Max: 6},
+			Size:    particle.Range{Min: 3, Max: 4},
+			EndSize: 0.3,
+			Spread:  math.Pi,
+			Area:    pixel.V(2, 2),
+			Drag:    2,
+		},
+		sparkle: particle.Emitter{
+			Life:   particle.Range{Min: 0.4, Max: 0.7},
+			Speed:  particle.Range{Min: 50, Max: 100},
+			Size:   particle.Range{Min: 5, Max: 5},
+			Spread: math.Pi,
+			Drag:   3,
+			Colors: []particle.Gradient{fade(colornames.White, colornames.Yellow, colornames.Orange)},
+			Frame:  starFrame,
+		},
+		paper: particle.Emitter{
+			Life:    particle.Range{Min: 2, Max: 3},
+			Speed:   particle.Range{Min: 20, Max: 60},
+			Size:    particle.Range{Min: 2, Max: 3},
+			Angle:   -math.Pi / 2,
+			Spread:  0.8,
+			Gravity: pixel.V(0, -90),
+			Drag:    1,
+		},
+	}
+	for h := 0.0; h < 360; h += 360.0 / 7 {
+		fx.rainbow.Colors = append(fx.rainbow.Colors, fade(hsvToRGB(h, 1, 1)))
+	}
+	for _, c := range []color.Color{colornames.Hotpink, colornames.Gold, colornames.Deepskyblue, colornames.Limegreen, colornames.White} {
+		fx.paper.Colors = append(fx.paper.Colors, particle.Colors(c, c, c, color.Transparent))
+	}
+	return fx
+}
+
+// starPicture draws the little four-pointed star sparkles are made of.
+func starPicture() *pixel.PictureData {
+	pic := pixel.MakePictureData(starFrame)
+	w := int(starFrame.W())
+	for i := 0; i < w; i++ {
+		pic.Pix[w*(w/2)+i] = color.RGBA{255, 255, 255, 255}
+		pic.Pix[w*i+w/2] = color.RGBA{255, 255, 255, 255}
+	}
+	return pic
+}
+
+// update moves every effect on by dt seconds.
+func (fx *effects) update(dt float64) {
+	fx.trail.Update(dt)
+	fx.sparks.Update(dt)
+	fx.confetti.Update(dt)
+}
+
+// clear removes every particle, for a fresh screen.
+func (fx *effects) clear() {
+	fx.trail.Clear()
+	fx.sparks.Clear()
+	fx.confetti.Clear()
+}
+
+// toot leaves rainbow behind the unicorn for the step it took from from.
+func (g *Game) toot(from pixel.Vec, dt float64) {
+	if !g.body.Moving() {
+		return
+	}
+	rear := pixel.V(g.cfg.UnicornSize*0.4, -g.cfg.UnicornSize*0.1)
+	if g.facingRight {
+		rear.X = -rear.X
+	}
+	g.fx.trail.Flow(&g.fx.rainbow, from.Add(rear), g.body.Pos.Add(rear), dt)
+}
+
+// sparkle bursts stars out of a pickup collected at p.
+func (g *Game) sparkle(p pixel.Vec) {
+	g.fx.sparks.Burst(&g.fx.sparkle, p, 12)
+}
+
+// throwConfetti showers confetti down from the top of the canvas.
+func (g *Game) throwConfetti() {
+	b := g.Bounds()
+	g.fx.paper.Area = pixel.V(b.W(), 30)
+	g.fx.confetti.Burst(&g.fx.paper, pixel.V(b.Center().X, b.Max.Y+15), 160)
+}
+
+// drawTrail draws the rainbow trail; call it within drawWorld.
+func (g *Game) drawTrail(t render.Target) { g.fx.trail.Draw(t) }
+
+// drawSparkles draws pickup sparkles; call it within drawWorld.
+func (g *Game) drawSparkles(t render.Target) { g.fx.sparks.Draw(t) }
@@ -0,0 +1,540 @@
+// Package game holds the Unicorn Toots game state and rules. It has no
+// dependency on a window or OpenGL context so it can be stepped and drawn
+// headlessly.
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"unicode/utf8"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"golang.org/x/image/font/basicfont"
+
+	"unicorn-toots/anim"
+	"unicorn-toots/audio"
+	"unicorn-toots/collide"
+	"unicorn-toots/config"
+	"unicorn-toots/input"
+	"unicorn-toots/motion"
+	"unicorn-toots/render"
+	"unicorn-toots/tilemap"
+)
+
+type Mode int
+
+const (
+	ModeMenu Mode = iota
+	ModeSpelling
+	ModeGem
+)
+
+var modeNames = [...]string{ModeMenu: "menu", ModeSpelling: "spelling", ModeGem: "gem"}
+
+func (m Mode) String() string {
+	if !m.Valid() {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// Valid reports whether m is one of the game's modes.
+func (m Mode) Valid() bool { return m >= 0 && int(m) < len(modeNames) }
+
+// ParseMode returns the mode called name: "menu", "spelling" or "gem".
+func ParseMode(name string) (Mode, error) {
+	for m, n := range modeNames {
+		if n == name {
+			return Mode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q (want menu, spelling or gem)", name)
+}
+
+type State int
+
+const (
+	StatePlaying State = iota
+	StateTryAgain
+	StateWordComplete
+)
+
+type Letter struct {
+	char      rune
+	pos       pixel.Vec
+	collected bool
+}
+
+func (l Letter) Char() rune      { return l.char }
+func (l Letter) Pos() pixel.Vec  { return l.pos }
+func (l Letter) Collected() bool { return l.collected }
+
+type Gem struct {
+	pos       pixel.Vec
+	collected bool
+}
+
+func (gm Gem) Pos() pixel.Vec  { return gm.pos }
+func (gm Gem) Collected() bool { return gm.collected }
+
+// Assets are the loaded sprites and word data the game draws from.
+type Assets struct {
+	Unicorn    *anim.Sheet
+	Gem        *pixel.Sprite
+	Words      []string
+	WordImages map[string]*pixel.Sprite
+
+	// Arenas are the playfields a mode may be played on. With none, modes
+	// are played on the open canvas.
+	Arenas []*tilemap.Map
+
+	// Sounds are the sound effects, by name. With none, the game is silent.
+	Sounds map[string]*audio.Sound
+
+	// WordSounds and LetterSounds are recordings of each word and letter
+	// being said, by the uppercase word or letter, for players who can't
+	// read the prompts yet.
+	WordSounds   map[string]*audio.Sound
+	LetterSounds map[string]*audio.Sound
+
+	// Problems lists files that failed to load and were replaced.
+	Problems []error
+}
+
+// Game is the complete state of a play session. The screens themselves live
+// on a scene stack; Game holds what they share, such as the unicorn.
+type Game struct {
+	Debug bool
+
+	assets Assets
+	cfg    config.Config
+	size   pixel.Rect // canvas bounds the game is laid out in
+	seed   int64
+	rng    *rand.Rand
+	sprite *pixel.Sprite
+	atlas  *text.Atlas
+	imd    *imdraw.IMDraw
+	bg     *Background
+	arena  *arena // the playfield of the current round, if any
+	fx     *effects
+	audio  audio.Backend
+	scenes Stack
+
+	// The unicorn, letters, gems and arena live in a world that may be
+	// larger than the canvas, seen through the camera.
+	world   pixel.Rect
+	cam     render.Camera
+	prevCam pixel.Vec // camera position before the last step, for interpolation
+
+	// reloadStatus describes the last hot reload, for the debug overlay.
+	reloadStatus string
+
+	body        motion.Body // the unicorn's position and velocity
+	prevPos     pixel.Vec   // position before the last step, for interpolation
+	alpha       float64
+	unicorn     *anim.Player
+	masks       map[pixel.Rect]*collide.Mask // unicorn frame masks, by frame
+	facingRight bool                         // the art faces left; facing right mirrors it
+	reaction    string                       // one-off clip playing over idle or walk, if any
+	holding     bool                         // the reaction plays until let go, not just once
+	noiseTime   float64
+}
+
+// New creates a game sitting on the main menu, tuned by cfg. All randomness
+// in the session comes from seed, so the same seed and inputs replay the
+// same game.
+func New(a Assets, cfg config.Config, seed int64) *Game {
+	rng := rand.New(rand.NewSource(seed))
+	g := &Game{
+		assets: a,
+		cfg:    cfg,
+		seed:   seed,
+		rng:    rng,
+		sprite: pixel.NewSprite(nil, pixel.Rect{}),
+		atlas:  text.NewAtlas(basicfont.Face7x13, text.ASCII),
+		imd:    imdraw.New(nil),
+		size:   pixel.R(0, 0, float64(cfg.CanvasWidth), float64(cfg.CanvasHeight)),
+		bg:     newBackground(rng, cfg.CanvasWidth, cfg.CanvasHeight, cfg.BgScale, cfg.NoiseScale),
+		fx:     newEffects(seed),
+		audio:  audio.Null{},
+		alpha:  1,
+		masks:  make(map[pixel.Rect]*collide.Mask),
+		cam: render.Camera{
+			View:     pixel.V(float64(cfg.CanvasWidth), float64(cfg.CanvasHeight)),
+			DeadZone: cfg.CameraDeadZone,
+			Lag:      cfg.CameraLag,
+		},
+	}
+	g.unicorn = anim.NewPlayer(a.Unicorn, cfg.FrameDuration)
+	g.setWorld(g.worldFor(g.size))
+	g.SetPos(g.world.Center())
+	g.scenes.Push(newMenuScene(g))
+	if len(a.Problems) > 0 {
+		g.scenes.Push(&diagnosticsScene{g: g})
+	}
+	return g
+}
+
+// Config returns the tunables the game is running with.
+func (g *Game) Config() config.Config { return g.cfg }
+
+// Bounds returns the area of the canvas the game lays itself out in.
+func (g *Game) Bounds() pixel.Rect { return g.size }
+
+// Resize lays the game out for new canvas bounds: menus re-center, the
+// camera shows more or less of the world, and the background is rebuilt at
+// the new size. A world that is only as big as the canvas grows or shrinks
+// with it, with the unicorn, letters and gems keeping their place relative
+// to its edges. Empty bounds, as reported by a minimized window, are
+// ignored.
+func (g *Game) Resize(b pixel.Rect) {
+	if b == g.size || b.W() < 1 || b.H() < 1 {
+		return
+	}
+	g.size = b
+	g.cam.View = b.Size()
+	g.bg = g.bg.resized(int(b.W()), int(b.H()))
+	g.setWorld(g.worldFor(b))
+	g.cam.Snap(g.cam.Pos)
+	g.prevCam = g.cam.Pos
+}
+
+// Seed returns the seed the session's randomness was derived from.
+func (g *Game) Seed() int64 { return g.seed }
+
+// Scenes returns the scene stack, for pushing custom screens.
+func (g *Game) Scenes() *Stack { return &g.scenes }
+
+// Mode reports which game mode is active underneath any overlays.
+func (g *Game) Mode() Mode {
+	for i := len(g.scenes.scenes) - 1; i >= 0; i-- {
+		switch g.scenes.scenes[i].(type) {
+		case *spellingScene:
+			return ModeSpelling
+		case *gemScene:
+			return ModeGem
+		}
+	}
+	return ModeMenu
+}
+
+// State reports the spelling overlay currently shown, if any.
+func (g *Game) State() State {
+	switch g.scenes.Top().(type) {
+	case *tryAgainScene:
+		return StateTryAgain
+	case *wordCompleteScene:
+		return StateWordComplete
+	}
+	return StatePlaying
+}
+
+// Word returns the word being spelled and how many of its letters have been
+// collected so far.
+func (g *Game) Word() (string, int) {
+	s, ok := find[*spellingScene](&g.scenes)
+	if !ok {
+		return "", 0
+	}
+	return s.word, s.nextLetterIdx
+}
+
+// Letters returns the letters on the field, in spelling order.
+func (g *Game) Letters() []Letter {
+	s, ok := find[*spellingScene](&g.scenes)
+	if !ok {
+		return nil
+	}
+	return s.letters
+}
+
+func (g *Game) Gems() []Gem {
+	s, ok := find[*gemScene](&g.scenes)
+	if !ok {
+		return nil
+	}
+	return s.gems
+}
+
+func (g *Game) GemScore() int {
+	s, ok := find[*gemScene](&g.scenes)
+	if !ok {
+		return 0
+	}
+	return s.score
+}
+
+func (g *Game) Pos() pixel.Vec { return g.body.Pos }
+
+// Vel returns the unicorn's velocity, in canvas pixels per second.
+func (g *Game) Vel() pixel.Vec { return g.body.Vel }
+
+// SetPos places the unicorn at pos, standing still, and brings the camera
+// straight to it, without interpolating from where either was.
+func (g *Game) SetPos(pos pixel.Vec) {
+	g.body = motion.Body{Pos: pos}
+	g.prevPos = pos
+	g.cam.Snap(pos)
+	g.prevCam = g.cam.Pos
+}
+
+// SetAlpha sets how far between the last two simulation steps the next Draw
+// should show moving things, from 0 (previous step) to 1 (latest step).
+func (g *Game) SetAlpha(alpha float64) { g.alpha = alpha }
+
+// StartSpelling switches to spelling mode with a freshly picked word, on a
+// randomly picked arena if there are any.
+func (g *Game) StartSpelling() {
+	g.toMenu()
+	g.pickArena()
+	g.scenes.Push(newSpellingScene(g))
+}
+
+// StartGem switches to gem mode with a new batch of gems, on a randomly
+// picked arena if there are any.
+func (g *Game) StartGem() {
+	g.toMenu()
+	g.pickArena()
+	g.scenes.Push(newGemScene(g))
+}
+
+// Start puts the game straight into mode m, as if it had been picked from
+// the menu. A diagnostics screen stays on top so load problems are still
+// seen first.
+func (g *Game) Start(m Mode) {
+	diag, hasDiag := find[*diagnosticsScene](&g.scenes)
+	switch m {
+	case ModeSpelling:
+		g.StartSpelling()
+	case ModeGem:
+		g.StartGem()
+	}
+	if hasDiag {
+		g.scenes.Push(diag)
+	}
+}
+
+// toMenu pops everything above the menu, brings the unicorn to a halt,
+// hushes any prompt being read out and clears away the arena and effects.
+func (g *Game) toMenu() {
+	g.scenes.PopTo(1)
+	g.body.Stop()
+	g.audio.Stop(audio.Speech)
+	g.fx.clear()
+	g.arena = nil
+	g.setWorld(g.worldFor(g.size))
+}
+
+// Update advances the game by dt seconds using the given input.
+func (g *Game) Update(dt float64, in input.State) {
+	g.noiseTime += dt
+	// A step that doesn't move the unicorn should draw it where it is, not
+	// part way from wherever it last moved from.
+	g.prevPos = g.body.Pos
+	g.scenes.Update(dt, in)
+	g.prevCam = g.cam.Pos
+	g.cam.Follow(g.body.Pos, dt)
+}
+
+// Draw renders the current frame onto t.
+func (g *Game) Draw(t render.Target) {
+	g.bg.update(g.noiseTime)
+	g.bg.draw(t)
+	if g.arena != nil {
+		g.drawWorld(t, func() { g.arena.draw(t) })
+	}
+	g.scenes.Draw(t)
+	if g.Debug {
+		g.drawDebug(t)
+	}
+}
+
+// moveUnicorn steers the unicorn by the movement controls and walks it, or
+// lets it idle once it has glided to a stop.
+func (g *Game) moveUnicorn(dt float64, in input.State) {
+	dir := in.Direction()
+	g.body.Step(motion.Tuned(g.cfg.MoveSpeed, g.cfg.Floatiness), dir, dt)
+
+	// Running into an edge or a wall stops the unicorn in that direction,
+	// so it doesn't stay pressed against it.
+	p := g.clampUnicorn(g.body.Pos)
+	if p.X != g.body.Pos.X {
+		g.body.Vel.X = 0
+	}
+	if p.Y != g.body.Pos.Y {
+		g.body.Vel.Y = 0
+	}
+	g.body.Pos = p
+	g.hitWalls(g.prevPos)
+
+	// Face the way it is steered, or while coasting the way it drifts.
+	heading := dir.X
+	if heading == 0 {
+		heading = g.body.Vel.X
+	}
+	if heading > 0 {
+		g.facingRight = true
+	} else if heading < 0 {
+		g.facingRight = false
+	}
+	// The trail comes out behind, so it needs the facing settled first.
+	g.toot(g.prevPos, dt)
+
+	clip := "walk"
+	if !g.body.Moving() {
+		clip = "idle"
+	}
+	if g.reaction != "" {
+		if !g.holding && g.unicorn.Played() {
+			g.reaction = ""
+		} else {
+			clip = g.reaction
+		}
+	}
+	g.animateUnicorn(clip, dt)
+}
+
+// react plays the unicorn's clip called name once over whatever it is
+// doing, then goes back to idling or walking. A sheet without that clip
+// shows no reaction.
+func (g *Game) react(name string) {
+	if g.assets.Unicorn.Clip(name) == nil {
+		return
+	}
+	g.reaction, g.holding = name, false
+	g.unicorn.Play(name)
+}
+
+// hold plays the unicorn's clip called name over whatever it is doing, as
+// react does, but keeps it going until letGo.
+func (g *Game) hold(name string) {
+	g.react(name)
+	g.holding = g.reaction == name
+}
+
+// letGo ends a held reaction.
+func (g *Game) letGo() {
+	if g.holding {
+		g.reaction, g.holding = "", false
+	}
+}
+
+// animateUnicorn plays the unicorn's clip called name, advancing it by dt.
+func (g *Game) animateUnicorn(name string, dt float64) {
+	g.unicorn.Play(name)
+	g.unicorn.Update(dt)
+}
+
+// clampUnicorn keeps a unicorn centred at p inside the world.
+func (g *Game) clampUnicorn(p pixel.Vec) pixel.Vec {
+	half := g.cfg.UnicornSize / 2
+	b := g.world
+	if p.X < b.Min.X+half {
+		p.X = b.Min.X + half
+	}
+	if p.X > b.Max.X-half {
+		p.X = b.Max.X - half
+	}
+	if p.Y < b.Min.Y+half {
+		p.Y = b.Min.Y + half
+	}
+	if p.Y > b.Max.Y-half {
+		p.Y = b.Max.Y - half
+	}
+	return p
+}
+
+// randomLetterPositions scatters word's letters over area, well apart from
+// each other and where open says there is room for them.
+func randomLetterPositions(rng *rand.Rand, word string, area pixel.Rect, open func(pixel.Vec) bool) []Letter {
+	letters := make([]Letter, utf8.RuneCountInString(word))
+	margin := 30.0
+	minDist := 70.0
+
+	i := 0
+	for _, ch := range word {
+		var pos pixel.Vec
+		for attempts := 0; attempts < 100; attempts++ {
+			pos = pixel.V(
+				area.Min.X+margin+rng.Float64()*(area.W()-2*margin),
+				area.Min.Y+margin+rng.Float64()*(area.H()-2*margin-30), // leave room for HUD at top
+			)
+			ok := open(pos)
+			for j := 0; ok && j < i; j++ {
+				if pos.Sub(letters[j].pos).Len() < minDist {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				break
+			}
+		}
+		letters[i] = Letter{char: ch, pos: pos, collected: false}
+		i++
+	}
+	return letters
+}
+
+// randomGemPositions scatters count gems over area, well apart from each
+// other and where open says there is room for them.
+func randomGemPositions(rng *rand.Rand, count int, area pixel.Rect, open func(pixel.Vec) bool) []Gem {
+	gems := make([]Gem, count)
+	margin := 30.0
+	minDist := 70.0
+
+	for i := 0; i < count; i++ {
+		var pos pixel.Vec
+		for attempts := 0; attempts < 100; attempts++ {
+			pos = pixel.V(
+				area.Min.X+margin+rng.Float64()*(area.W()-2*margin),
+				area.Min.Y+margin+rng.Float64()*(area.H()-2*margin-30),
+			)
+			ok := open(pos)
+			for j := 0; ok && j < i; j++ {
+				if pos.Sub(gems[j].pos).Len() < minDist {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				break
+			}
+		}
+		gems[i] = Gem{pos: pos, collected: false}
+	}
+	return gems
+}
+
+func hsvToRGB(h, s, v float64) color.RGBA {
+	h = math.Mod(h, 360)
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var r1, g1, b1 float64
+	switch {
+	case h < 60:
+		r1, g1, b1 = c, x, 0
+	case h < 120:
+		r1, g1, b1 = x, c, 0
+	case h < 180:
+		r1, g1, b1 = 0, c, x
+	case h < 240:
+		r1, g1, b1 = 0, x, c
+	case h < 300:
+		r1, g1, b1 = x, 0, c
+	default:
+		r1, g1, b1 = c, 0, x
+	}
+	return color.RGBA{
+		R: uint8((r1 + m) * 255),
+		G: uint8((g1 + m) * 255),
+		B: uint8((b1 + m) * 255),
+		A: 255,
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/assets"
+	"unicorn-toots/config"
+	"unicorn-toots/input"
+)
+
+const step = 1.0 / TickRate
+
+func newTestGame() *Game {
+	return New(LoadAssets(assets.NewManager("")), config.Default(), 1)
+}
+
+// touch puts the unicorn on the i'th letter of the word for one step.
+func touch(i int) func(g *Game) {
+	return func(g *Game) {
+		g.SetPos(g.Letters()[i].Pos())
+		g.Update(step, input.State{})
+	}
+}
+
+// wait runs idle steps for d seconds, rounded to whole steps.
+func wait(d float64) func(g *Game) {
+	return func(g *Game) {
+		for i := 0; i < int(math.Round(d/step)); i++ {
+			g.Update(step, input.State{})
+		}
+	}
+}
+
+func TestSpelling(t *testing.T) {
+	g := newTestGame()
+	g.StartSpelling()
+	word, _ := g.Word()
+	if word != "SHIP" {
+		t.Fatalf("first word %q, want SHIP", word)
+	}
+	cfg := g.Config()
+
+	steps := []struct {
+		name      string
+		do        func(g *Game)
+		state     State
+		next      int  // letters collected so far
+		scattered bool // whether the letters should have moved
+	}{
+		{"first letter", touch(0), StatePlaying, 1, false},
+		{"second letter", touch(1), StatePlaying, 2, false},
+		{"wrong letter", touch(3), StateTryAgain, 2, false},
+		{"still sorry", wait(cfg.TryAgainDelay / 2), StateTryAgain, 2, false},
+		// The overlay's timer adds up steps, so give it one over the delay.
+		{"reshuffled", wait(cfg.TryAgainDelay/2 + step), StatePlaying, 0, true},
+		{"start over", touch(0), StatePlaying, 1, false},
+		{"spelled", func(g *Game) {
+			for i := 1; i < len(g.Letters()); i++ {
+				touch(i)(g)
+			}
+		}, StateWordComplete, 4, false},
+		{"celebrating", wait(cfg.WordCompleteDelay / 2), StateWordComplete, 4, false},
+		{"new word", wait(cfg.WordCompleteDelay/2 + step), StatePlaying, 0, true},
+	}
+	for _, st := range steps {
+		before := append([]Letter(nil), g.Letters()...)
+		st.do(g)
+		if got := g.State(); got != st.state {
+			t.Fatalf("%s: state %v, want %v", st.name, got, st.state)
+		}
+		word, next := g.Word()
+		if next != st.next {
+			t.Errorf("%s: %d letters collected, want %d", st.name, next, st.next)
+		}
+		letters := g.Letters()
+		if len(letters) != len(word) {
+			t.Fatalf("%s: %d letters for %q", st.name, len(letters), word)
+		}
+		for i, l := range letters {
+			if l.Char() != rune(word[i]) || l.Collected() != (i < next) {
+				t.Errorf("%s: letter %d is %q, collected %v", st.name, i, l.Char(), l.Collected())
+			}
+		}
+		if moved := !samePlaces(before, letters); moved != st.scattered {
+			t.Errorf("%s: letters moved %v, want %v", st.name, moved, st.scattered)
+		}
+	}
+	if g.Mode() != ModeSpelling {
+		t.Errorf("mode %v after a word, want spelling", g.Mode())
+	}
+}
+
+// samePlaces reports whether two sets of letters lie in the same places.
+func samePlaces(a, b []Letter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Pos() != b[i].Pos() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGemBatches(t *testing.T) {
+	g := newTestGame()
+	g.StartGem()
+	first := append([]Gem(nil), g.Gems()...)
+	if len(first) != g.Config().GemsPerBatch {
+		t.Fatalf("%d gems, want %d", len(first), g.Config().GemsPerBatch)
+	}
+
+	for i := range first[:len(first)-1] {
+		g.SetPos(first[i].Pos())
+		g.Update(step, input.State{})
+		if g.GemScore() != i+1 || !g.Gems()[i].Collected() {
+			t.Fatalf("after gem %d the score is %d", i, g.GemScore())
+		}
+	}
+	if !reflect.DeepEqual(gemPlaces(g.Gems()), gemPlaces(first)) {
+		t.Fatal("gems moved before the batch was done")
+	}
+
+	g.SetPos(first[len(first)-1].Pos())
+	g.Update(step, input.State{})
+	if g.GemScore() != len(first) {
+		t.Errorf("score %d after the batch, want %d", g.GemScore(), len(first))
+	}
+	batch := g.Gems()
+	if len(batch) != len(first) {
+		t.Fatalf("new batch has %d gems, want %d", len(batch), len(first))
+	}
+	for i, gem := range batch {
+		if gem.Collected() {
+			t.Errorf("gem %d of the new batch is already collected", i)
+		}
+	}
+	if reflect.DeepEqual(gemPlaces(batch), gemPlaces(first)) {
+		t.Error("new batch is where the last one was")
+	}
+}
+
+func gemPlaces(gems []Gem) []pixel.Vec {
+	var at []pixel.Vec
+	for _, gem := range gems {
+		at = append(at, gem.Pos())
+	}
+	return at
+}
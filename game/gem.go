@@ -0,0 +1,128 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/collide"
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// gemScene has the unicorn collect batches of gems for points.
+type gemScene struct {
+	g     *Game
+	gems  []Gem
+	score int
+	space *collide.Space // gems still on the field, by index
+}
+
+func newGemScene(g *Game) *gemScene {
+	return &gemScene{g: g}
+}
+
+func (s *gemScene) Enter() {
+	s.newBatch()
+	s.score = 0
+	s.g.SetPos(s.g.startPos())
+}
+
+func (s *gemScene) Exit() {}
+
+// newBatch scatters a fresh batch of gems.
+func (s *gemScene) newBatch() {
+	s.gems = s.scatter()
+	s.space = collide.NewSpace(cellSize)
+	s.place()
+}
+
+// scatter lays a batch out on the arena's "gem" spawn points if it has
+// enough of them, or anywhere open otherwise.
+func (s *gemScene) scatter() []Gem {
+	n := s.g.cfg.GemsPerBatch
+	if at := s.g.spawnPoints("gem", n); at != nil {
+		gems := make([]Gem, n)
+		for i := range gems {
+			gems[i].pos = at[i]
+		}
+		return gems
+	}
+	return randomGemPositions(s.g.rng, n, s.g.world, func(p pixel.Vec) bool {
+		return s.g.open(p, s.g.cfg.GemSize)
+	})
+}
+
+// place puts the gems still on the field into the collision space.
+func (s *gemScene) place() {
+	for i, gem := range s.gems {
+		if !gem.collected {
+			s.space.Set(collide.ID(i), pickupBody(gem.pos, s.g.cfg.GemSize))
+		}
+	}
+}
+
+// resize keeps the gems where they were relative to the window.
+func (s *gemScene) resize(remap func(pixel.Vec) pixel.Vec) {
+	for i := range s.gems {
+		s.gems[i].pos = remap(s.gems[i].pos)
+	}
+	s.place()
+}
+
+func (s *gemScene) Update(dt float64, in input.State) {
+	s.g.fx.update(dt)
+	s.g.moveUnicorn(dt, in)
+
+	// Back to menu with Escape
+	if in.Back {
+		s.g.toMenu()
+		return
+	}
+	if in.Pause {
+		s.g.scenes.Push(&pauseScene{g: s.g})
+		return
+	}
+
+	for _, id := range s.g.touchPickups(s.space) {
+		s.gems[id].collected = true
+		s.space.Remove(id)
+		s.g.sparkle(s.gems[id].pos)
+		s.g.cue(soundGem)
+		s.score++
+	}
+
+	// Check if all gems collected, spawn new batch
+	allCollected := true
+	for _, gem := range s.gems {
+		if !gem.collected {
+			allCollected = false
+			break
+		}
+	}
+	if allCollected {
+		s.newBatch()
+	}
+}
+
+func (s *gemScene) Draw(t render.Target) {
+	s.g.drawWorld(t, func() {
+		for _, gem := range s.gems {
+			if gem.collected {
+				continue
+			}
+			s.g.assets.Gem.Draw(t, pixel.IM.Moved(gem.pos.Floor()))
+		}
+		s.g.drawTrail(t)
+		s.g.drawUnicorn(t)
+		s.g.drawSparkles(t)
+	})
+
+	// Draw HUD - gem count
+	hudTxt := text.New(pixel.V(5, s.g.Bounds().Max.Y-15), s.g.atlas)
+	hudTxt.Color = colornames.Yellow
+	fmt.Fprintf(hudTxt, "Gems: %d", s.score)
+	hudTxt.Draw(t, pixel.IM)
+}
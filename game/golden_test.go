@@ -0,0 +1,261 @@
+package game_test
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/assets"
+	"unicorn-toots/audio"
+	"unicorn-toots/config"
+	"unicorn-toots/game"
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// The golden tests render known game frames with the software renderer and
+// compare them against the PNGs in testdata. Accept the current frames with
+//
+//	go test ./game -run Golden -update
+var update = flag.Bool("update", false, "overwrite the golden images with the current frames")
+
+const (
+	step = 1.0 / 60
+
+	// tolerance is the largest per-channel difference that doesn't count as
+	// a changed pixel.
+	tolerance = 2
+)
+
+type scene struct {
+	name  string
+	setup func(g *game.Game)
+
+	// assets overrides the built-in assets for this scene.
+	assets string
+
+	// window, if set, shows the canvas letterboxed in a window this size.
+	window pixel.Rect
+
+	// tune, if set, adjusts the default config for this scene.
+	tune func(c *config.Config)
+
+	// sounds, if set, are the sound effects the scene should play, in order.
+	sounds []string
+}
+
+var scenes = []scene{
+	{name: "menu", setup: func(g *game.Game) {
+		g.Update(0.5, input.State{})
+	}},
+	{name: "spelling", sounds: []string{"words/SHIP"}, setup: func(g *game.Game) {
+		g.StartSpelling()
+		g.Update(step, input.State{})
+	}},
+	{name: "repeat_prompt", sounds: []string{"words/SHIP", "words/SHIP"}, setup: func(g *game.Game) {
+		g.StartSpelling()
+		g.Update(step, input.State{Repeat: true})
+	}},
+	{name: "walking_right", sounds: []string{}, setup: func(g *game.Game) {
+		g.StartGem()
+		for i := 0; i < 20; i++ {
+			g.Update(step, input.State{Right: true})
+		}
+	}},
+	{name: "try_again", sounds: []string{"words/SHIP", "wrong"}, setup: func(g *game.Game) {
+		g.StartSpelling()
+		g.SetPos(g.Letters()[1].Pos())
+		g.Update(step, input.State{})
+	}},
+	{name: "word_complete", sounds: []string{
+		"words/SHIP",
+		"letters/S", "letter", "letters/H", "letter", "letters/I", "letter",
+		"letters/P", "word",
+	}, setup: func(g *game.Game) {
+		g.StartSpelling()
+		for _, l := range g.Letters() {
+			g.SetPos(l.Pos())
+			g.Update(step, input.State{})
+		}
+		g.Update(0.5, input.State{})
+	}},
+	{name: "gems", setup: func(g *game.Game) {
+		g.StartGem()
+		g.Update(step, input.State{})
+	}},
+	{name: "gem_sparkle", sounds: []string{"gem"}, setup: func(g *game.Game) {
+		g.StartGem()
+		g.SetPos(g.Gems()[0].Pos())
+		for i := 0; i < 15; i++ {
+			g.Update(step, input.State{Left: true})
+		}
+	}},
+	{name: "pause", setup: func(g *game.Game) {
+		g.StartGem()
+		g.Update(step, input.State{Pause: true})
+	}},
+	{name: "debug_overlay", setup: func(g *game.Game) {
+		g.Debug = true
+		g.Update(0.5, input.State{})
+	}},
+	{name: "diagnostics", assets: "testdata/broken", setup: func(g *game.Game) {
+		g.Update(step, input.State{})
+	}},
+	{name: "arena_tmx", assets: "testdata/arena_tmx", setup: func(g *game.Game) {
+		g.StartSpelling()
+		for i := 0; i < 60; i++ {
+			g.Update(step, input.State{Up: true})
+		}
+	}},
+	{name: "arena_json", assets: "testdata/arena_json", setup: func(g *game.Game) {
+		g.StartGem()
+		for i := 0; i < 90; i++ {
+			g.Update(step, input.State{Right: true, Down: true})
+		}
+	}},
+	{name: "scrolling", tune: func(c *config.Config) {
+		c.WorldWidth, c.WorldHeight = 1200, 900
+	}, setup: func(g *game.Game) {
+		g.StartSpelling()
+		for i := 0; i < 150; i++ {
+			g.Update(step, input.State{Right: true, Down: true})
+		}
+	}},
+	{name: "gems_resized", setup: func(g *game.Game) {
+		g.StartGem()
+		g.Update(step, input.State{})
+		g.Resize(pixel.R(0, 0, 512, 240))
+		g.Update(step, input.State{})
+	}},
+	{name: "menu_resized", setup: func(g *game.Game) {
+		g.Resize(pixel.R(0, 0, 250, 350))
+		g.Update(0.5, input.State{})
+	}},
+	{name: "settings", setup: func(g *game.Game) {
+		g.Update(step, input.State{Click: true, ClickPos: g.Bounds().Center().Sub(pixel.V(0, 60))})
+	}},
+	{name: "letterboxed", window: pixel.R(0, 0, 1000, 700), setup: func(g *game.Game) {
+		g.StartSpelling()
+		g.Update(step, input.State{})
+	}},
+}
+
+func TestGolden(t *testing.T) {
+	for _, sc := range scenes {
+		t.Run(sc.name, func(t *testing.T) {
+			frame, played := renderScene(sc)
+			if sc.sounds != nil && !slices.Equal(played, sc.sounds) {
+				t.Errorf("played sounds %q, want %q", played, sc.sounds)
+			}
+
+			path := filepath.Join("testdata", sc.name+".png")
+			if *update {
+				if err := writePNG(path, frame); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := readPNG(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, changed := compare(want, frame)
+			if changed == 0 {
+				return
+			}
+			actualPath := filepath.Join(os.TempDir(), "golden-"+sc.name+"-actual.png")
+			diffPath := filepath.Join(os.TempDir(), "golden-"+sc.name+"-diff.png")
+			writePNG(actualPath, frame)
+			writePNG(diffPath, diff)
+			t.Errorf("%d pixels differ (see %s)", changed, diffPath)
+		})
+	}
+}
+
+// renderScene plays sc and returns its last frame and the sound effects it
+// played.
+func renderScene(sc scene) (*image.RGBA, []string) {
+	cfg := config.Default()
+	if sc.tune != nil {
+		sc.tune(&cfg)
+	}
+	g := game.New(game.LoadAssets(assets.NewManager(sc.assets)), cfg, 1)
+	var sounds audio.Recorder
+	g.SetAudio(&sounds)
+	sc.setup(g)
+
+	target := render.NewImage(g.Bounds())
+	target.Clear(color.Black)
+	g.Draw(target)
+	if sc.window == (pixel.Rect{}) {
+		return target.RGBA(), sounds.Played()
+	}
+
+	vp := render.Fit(sc.window, g.Bounds().Size(), true)
+	win := render.NewImage(sc.window)
+	win.Clear(color.Black)
+	pic := pixel.PictureDataFromImage(target.RGBA())
+	pixel.NewSprite(pic, pic.Bounds()).Draw(win, vp.Matrix())
+	return win.RGBA(), sounds.Played()
+}
+
+// compare returns an image highlighting changed pixels in red, and how many
+// there were.
+func compare(want, got image.Image) (*image.RGBA, int) {
+	b := got.Bounds()
+	diff := image.NewRGBA(b)
+	if want.Bounds() != b {
+		return diff, b.Dx() * b.Dy()
+	}
+	changed := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			g := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+			if absDiff(w.R, g.R) > tolerance || absDiff(w.G, g.G) > tolerance ||
+				absDiff(w.B, g.B) > tolerance || absDiff(w.A, g.A) > tolerance {
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				changed++
+			} else {
+				diff.SetRGBA(x, y, color.RGBA{g.R / 4, g.G / 4, g.B / 4, 255})
+			}
+		}
+	}
+	return diff, changed
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,60 @@
+package game
+
+import "unicorn-toots/input"
+
+const (
+	// TickRate is how many simulation steps run per second.
+	TickRate = 60
+	// maxCatchUp bounds how many steps a single slow frame may trigger, so
+	// a window drag or hitch skips time instead of teleporting the unicorn.
+	maxCatchUp = 5
+)
+
+// Loop converts variable frame times into fixed-size simulation steps.
+// Rendering happens once per frame regardless of how many steps ran.
+type Loop struct {
+	Step     float64 // seconds per simulation step
+	MaxSteps int     // most steps run for one frame; time beyond is dropped
+
+	acc   float64
+	carry input.State // one-shot controls from frames that ran no steps
+}
+
+// NewLoop returns a loop stepping at TickRate.
+func NewLoop() *Loop {
+	return &Loop{Step: 1.0 / TickRate, MaxSteps: maxCatchUp}
+}
+
+// Advance adds elapsed wall-clock seconds and calls tick once per whole step
+// now due. One-shot controls such as clicks are delivered to exactly one
+// step, even when a frame runs several steps or none. It returns the number
+// of steps run.
+func (l *Loop) Advance(elapsed float64, in input.State, tick func(input.State)) int {
+	if elapsed > 0 {
+		l.acc += elapsed
+	}
+	if limit := float64(l.MaxSteps) * l.Step; l.acc > limit {
+		l.acc = limit
+	}
+
+	in = in.Merge(l.carry)
+	steps := 0
+	for l.acc >= l.Step {
+		l.acc -= l.Step
+		tick(in)
+		in = in.Held()
+		steps++
+	}
+	if steps == 0 {
+		l.carry = in.Edges()
+	} else {
+		l.carry = input.State{}
+	}
+	return steps
+}
+
+// Alpha returns how far the simulation is into the next step, from 0 to 1,
+// for interpolating what gets drawn.
+func (l *Loop) Alpha() float64 {
+	return l.acc / l.Step
+}
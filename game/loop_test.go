@@ -0,0 +1,156 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/assets"
+	"unicorn-toots/config"
+	"unicorn-toots/input"
+)
+
+// frames returns a frame-time function repeating d, with a frame of hitch
+// seconds once the given number of frames have passed.
+func frames(d float64, hitchAt int, hitch float64) func(i int) float64 {
+	return func(i int) float64 {
+		if i == hitchAt {
+			return hitch
+		}
+		return d
+	}
+}
+
+// playSteps runs a new game through l, one frame of frame(i) seconds at a
+// time, until n steps have run. It returns the checksum after step n and how
+// many frames that took. The unicorn walks up and right for the first
+// second, then left.
+func playSteps(t *testing.T, l *Loop, frame func(i int) float64, n int) (uint64, int) {
+	t.Helper()
+	g := New(LoadAssets(assets.NewManager("")), config.Default(), 1)
+	g.StartSpelling()
+	steps := 0
+	var sum uint64
+	i := 0
+	for ; steps < n; i++ {
+		if i > 100*n {
+			t.Fatalf("only %d of %d steps ran", steps, n)
+		}
+		in := input.State{Right: true, Up: true}
+		if steps >= TickRate {
+			in = input.State{Left: true}
+		}
+		l.Advance(frame(i), in, func(in input.State) {
+			g.Update(l.Step, in)
+			if steps++; steps == n {
+				sum = g.Checksum()
+			}
+		})
+	}
+	return sum, i
+}
+
+func TestLoopFrameSplits(t *testing.T) {
+	const n = 2 * TickRate
+	want, _ := playSteps(t, NewLoop(), frames(1.0/TickRate, -1, 0), n)
+
+	tests := []struct {
+		name  string
+		frame func(i int) float64
+	}{
+		{"30 fps", frames(1.0/30, -1, 0)},
+		{"144 fps", frames(1.0/144, -1, 0)},
+		{"uneven", func(i int) float64 { return []float64{0.004, 0.02, 0.011}[i%3] }},
+		{"hitch", frames(1.0/TickRate, 10, 0.5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLoop()
+			l.MaxSteps = n // no catching up is dropped here
+			if got, _ := playSteps(t, l, tt.frame, n); got != want {
+				t.Errorf("checksum %#x, want %#x as at 60 fps", got, want)
+			}
+		})
+	}
+}
+
+func TestLoopCatchUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed []float64
+		steps   []int
+		alpha   float64 // after the last frame
+	}{
+		{"one step", []float64{1.0 / 60}, []int{1}, 0},
+		{"half steps add up", []float64{1.0 / 120, 1.0 / 120}, []int{0, 1}, 0},
+		{"remainder kept", []float64{1.0 / 40}, []int{1}, 0.5},
+		{"hitch capped", []float64{0.5}, []int{maxCatchUp}, 0},
+		{"capped then on", []float64{0.5, 1.0 / 120}, []int{maxCatchUp, 0}, 0.5},
+		{"backwards clock", []float64{1.0 / 120, -1, 1.0 / 120}, []int{0, 0, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLoop()
+			for i, e := range tt.elapsed {
+				if got := l.Advance(e, input.State{}, func(input.State) {}); got != tt.steps[i] {
+					t.Errorf("frame %d ran %d steps, want %d", i, got, tt.steps[i])
+				}
+			}
+			if a := l.Alpha(); math.Abs(a-tt.alpha) > 1e-9 {
+				t.Errorf("alpha %v, want %v", a, tt.alpha)
+			}
+		})
+	}
+
+	// The time the cap drops is gone for good: after a half-second hitch the
+	// game is maxCatchUp steps on, not 30, and carries on from there as if
+	// the frame had been that short.
+	const n = 30
+	steady, _ := playSteps(t, NewLoop(), frames(1.0/TickRate, -1, 0), n)
+	hitched, took := playSteps(t, NewLoop(), frames(1.0/TickRate, 0, 0.5), n)
+	if hitched != steady {
+		t.Errorf("checksum %#x after a hitch, want %#x", hitched, steady)
+	}
+	if want := 1 + n - maxCatchUp; took != want {
+		t.Errorf("%d steps took %d frames after a hitch, want %d", n, took, want)
+	}
+}
+
+func TestLoopOneShots(t *testing.T) {
+	click := input.State{Click: true, ClickPos: pixel.V(3, 4), Right: true}
+	tests := []struct {
+		name    string
+		elapsed []float64
+		in      []input.State
+		want    []input.State // what each step saw
+	}{
+		{"one step", []float64{1.0 / 60}, []input.State{click}, []input.State{click}},
+		{"several steps", []float64{3.0 / 60}, []input.State{click}, []input.State{
+			click, {Right: true}, {Right: true},
+		}},
+		{"carried over a short frame", []float64{1.0 / 144, 1.0 / 144, 1.0 / 144}, []input.State{click, {}, {}}, []input.State{
+			{Click: true, ClickPos: pixel.V(3, 4)},
+		}},
+		{"carry cleared once delivered", []float64{1.0 / 120, 1.0 / 120, 1.0 / 60}, []input.State{{Pause: true}, {}, {}}, []input.State{
+			{Pause: true}, {},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLoop()
+			var got []input.State
+			for i, e := range tt.elapsed {
+				l.Advance(e, tt.in[i], func(in input.State) { got = append(got, in) })
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("steps saw %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("step %d saw %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// menuScene is the title screen and the bottom of the scene stack.
+type menuScene struct {
+	g           *Game
+	spellingBtn button
+	gemBtn      button
+	settingsBtn button
+}
+
+func newMenuScene(g *Game) *menuScene {
+	m := &menuScene{
+		g:           g,
+		spellingBtn: button{label: "SPELLING MODE", color: colornames.Darkgreen},
+		gemBtn:      button{label: "GEM MODE", color: colornames.Darkblue},
+		settingsBtn: button{label: "SETTINGS", color: colornames.Dimgray},
+	}
+	m.layout()
+	return m
+}
+
+// layout stacks the buttons in the middle of the window.
+func (m *menuScene) layout() {
+	c := m.g.Bounds().Center()
+	m.spellingBtn.rect = pixel.R(c.X-75, c.Y-5, c.X+75, c.Y+25)
+	m.gemBtn.rect = pixel.R(c.X-75, c.Y-40, c.X+75, c.Y-10)
+	m.settingsBtn.rect = pixel.R(c.X-75, c.Y-75, c.X+75, c.Y-45)
+}
+
+func (m *menuScene) resize(func(pixel.Vec) pixel.Vec) { m.layout() }
+
+func (m *menuScene) Enter() {}
+func (m *menuScene) Exit()  {}
+
+func (m *menuScene) Update(dt float64, in input.State) {
+	// Check for button clicks
+	if !in.Click {
+		return
+	}
+	switch {
+	case m.spellingBtn.rect.Contains(in.ClickPos):
+		m.g.StartSpelling()
+	case m.gemBtn.rect.Contains(in.ClickPos):
+		m.g.StartGem()
+	case m.settingsBtn.rect.Contains(in.ClickPos):
+		m.g.scenes.Push(newSettingsScene(m.g))
+	}
+}
+
+func (m *menuScene) Draw(t render.Target) {
+	m.g.drawCentered(t, "UNICORN TOOTS", m.g.Bounds().Center().Add(pixel.V(0, 60)), 2, colornames.Yellow)
+
+	m.g.drawButton(t, m.spellingBtn)
+	m.g.drawButton(t, m.gemBtn)
+	m.g.drawButton(t, m.settingsBtn)
+}
+
+// settingsScene lets a grown-up toggle options from the menu.
+type settingsScene struct {
+	g        *Game
+	debugBtn button
+	backBtn  button
+}
+
+func newSettingsScene(g *Game) *settingsScene {
+	s := &settingsScene{
+		g:        g,
+		debugBtn: button{color: colornames.Darkslategray},
+		backBtn:  button{label: "BACK", color: colornames.Dimgray},
+	}
+	s.layout()
+	return s
+}
+
+// layout lines the buttons up where the menu's first two buttons sit.
+func (s *settingsScene) layout() {
+	c := s.g.Bounds().Center()
+	s.debugBtn.rect = pixel.R(c.X-75, c.Y-5, c.X+75, c.Y+25)
+	s.backBtn.rect = pixel.R(c.X-75, c.Y-40, c.X+75, c.Y-10)
+}
+
+func (s *settingsScene) resize(func(pixel.Vec) pixel.Vec) { s.layout() }
+
+func (s *settingsScene) Enter() {}
+func (s *settingsScene) Exit()  {}
+
+func (s *settingsScene) Update(dt float64, in input.State) {
+	if in.Back || (in.Click && s.backBtn.rect.Contains(in.ClickPos)) {
+		s.g.scenes.Pop()
+		return
+	}
+	if in.Click && s.debugBtn.rect.Contains(in.ClickPos) {
+		s.g.Debug = !s.g.Debug
+	}
+}
+
+func (s *settingsScene) Draw(t render.Target) {
+	s.g.drawCentered(t, "SETTINGS", s.g.Bounds().Center().Add(pixel.V(0, 60)), 2, colornames.Yellow)
+
+	s.debugBtn.label = "DEBUG: OFF"
+	if s.g.Debug {
+		s.debugBtn.label = "DEBUG: ON"
+	}
+	s.g.drawButton(t, s.debugBtn)
+	s.g.drawButton(t, s.backBtn)
+}
+
+// pauseScene freezes the game underneath it until play resumes.
+type pauseScene struct {
+	g *Game
+}
+
+func (p *pauseScene) Enter()     {}
+func (p *pauseScene) Exit()      {}
+func (p *pauseScene) isOverlay() {}
+
+func (p *pauseScene) Update(dt float64, in input.State) {
+	switch {
+	case in.Back:
+		p.g.toMenu()
+	case in.Pause:
+		p.g.scenes.Pop()
+	}
+}
+
+func (p *pauseScene) Draw(t render.Target) {
+	p.g.drawDim(t)
+	c := p.g.Bounds().Center()
+	p.g.drawCentered(t, "Paused", c.Add(pixel.V(0, 10)), 2, colornames.White)
+	p.g.drawCentered(t, "P to play, Esc for menu", c.Sub(pixel.V(0, 20)), 1, colornames.White)
+}
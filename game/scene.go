@@ -0,0 +1,121 @@
+package game
+
+import (
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// Scene is one screen of the game. Only the top scene of the stack receives
+// updates; drawing starts at the highest scene that isn't an overlay so
+// overlays show the screen beneath them.
+type Scene interface {
+	Enter()
+	Exit()
+	Update(dt float64, in input.State)
+	Draw(t render.Target)
+}
+
+// overlay is implemented by scenes that draw on top of the scene beneath
+// them rather than replacing it.
+type overlay interface {
+	Scene
+	isOverlay()
+}
+
+// resizer is implemented by scenes that lay themselves out from the window
+// bounds. remap moves a point from the old bounds to the same relative spot
+// in the new ones.
+type resizer interface {
+	resize(remap func(pixel.Vec) pixel.Vec)
+}
+
+// Stack is a push/pop stack of scenes.
+type Stack struct {
+	scenes []Scene
+}
+
+// Push makes s the active scene.
+func (st *Stack) Push(s Scene) {
+	st.scenes = append(st.scenes, s)
+	s.Enter()
+}
+
+// Pop removes the active scene, returning it. Popping an empty stack returns
+// nil.
+func (st *Stack) Pop() Scene {
+	if len(st.scenes) == 0 {
+		return nil
+	}
+	top := st.scenes[len(st.scenes)-1]
+	st.scenes = st.scenes[:len(st.scenes)-1]
+	top.Exit()
+	return top
+}
+
+// Replace swaps the active scene for s.
+func (st *Stack) Replace(s Scene) {
+	st.Pop()
+	st.Push(s)
+}
+
+// PopTo pops scenes until only the bottom n remain.
+func (st *Stack) PopTo(n int) {
+	for len(st.scenes) > n {
+		st.Pop()
+	}
+}
+
+// Top returns the active scene, or nil if the stack is empty.
+func (st *Stack) Top() Scene {
+	if len(st.scenes) == 0 {
+		return nil
+	}
+	return st.scenes[len(st.scenes)-1]
+}
+
+// Len returns the number of scenes on the stack.
+func (st *Stack) Len() int { return len(st.scenes) }
+
+// Update advances the active scene.
+func (st *Stack) Update(dt float64, in input.State) {
+	if top := st.Top(); top != nil {
+		top.Update(dt, in)
+	}
+}
+
+// Draw draws the active scene along with any scenes it overlays.
+func (st *Stack) Draw(t render.Target) {
+	first := len(st.scenes) - 1
+	for first > 0 {
+		if _, ok := st.scenes[first].(overlay); !ok {
+			break
+		}
+		first--
+	}
+	for i := max(first, 0); i < len(st.scenes); i++ {
+		st.scenes[i].Draw(t)
+	}
+}
+
+// resize lets every scene on the stack, not just the active one, follow a
+// change of window bounds.
+func (st *Stack) resize(remap func(pixel.Vec) pixel.Vec) {
+	for _, s := range st.scenes {
+		if r, ok := s.(resizer); ok {
+			r.resize(remap)
+		}
+	}
+}
+
+// find returns the topmost scene of type T on the stack.
+func find[T Scene](st *Stack) (T, bool) {
+	for i := len(st.scenes) - 1; i >= 0; i-- {
+		if s, ok := st.scenes[i].(T); ok {
+			return s, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"maps"
+	"unicode"
+
+	"unicorn-toots/audio"
+)
+
+// Sound effects, by file name in the assets' sounds directory.
+const (
+	soundLetter = "letter" // the right letter collected
+	soundWrong  = "wrong"  // a letter collected out of order
+	soundWord   = "word"   // the last letter of a word collected
+	soundGem    = "gem"    // a gem collected
+)
+
+// Spoken words and letters are named in SoundBank by these prefixes and
+// the uppercase word or letter.
+const (
+	wordPrefix   = "words/"
+	letterPrefix = "letters/"
+)
+
+func wordSound(word string) string { return wordPrefix + word }
+func letterSound(ch rune) string   { return letterPrefix + string(unicode.ToUpper(ch)) }
+
+// SoundBank returns every sound the game may ask to play, by name: the
+// sound effects, and the spoken words and letters. It is what a mixer
+// playing the game should be given.
+func (a Assets) SoundBank() map[string]*audio.Sound {
+	bank := make(map[string]*audio.Sound, len(a.Sounds)+len(a.WordSounds)+len(a.LetterSounds))
+	maps.Copy(bank, a.Sounds)
+	for w, s := range a.WordSounds {
+		bank[wordPrefix+w] = s
+	}
+	for l, s := range a.LetterSounds {
+		bank[letterPrefix+l] = s
+	}
+	return bank
+}
+
+// SetAudio sends the game's sounds to out. A new game is silent until it is
+// given one.
+func (g *Game) SetAudio(out audio.Backend) { g.audio = out }
+
+// cue plays the sound effect called name.
+func (g *Game) cue(name string) { g.audio.Play(name, audio.Effects) }
+
+// say reads out the recording called name, cutting off whatever was being
+// said before so prompts never talk over each other.
+func (g *Game) say(name string) {
+	g.audio.Stop(audio.Speech)
+	g.audio.Play(name, audio.Speech)
+}
@@ -0,0 +1,267 @@
+package game
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/collide"
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+)
+
+// spellingScene has the unicorn collect the letters of a word in order.
+type spellingScene struct {
+	g             *Game
+	word          string
+	letters       []Letter
+	nextLetterIdx int
+	space         *collide.Space // letters still on the field, by index
+}
+
+func newSpellingScene(g *Game) *spellingScene {
+	return &spellingScene{g: g}
+}
+
+func (s *spellingScene) Enter() {
+	s.nextWord()
+	s.g.SetPos(s.g.startPos())
+}
+
+func (s *spellingScene) Exit() {}
+
+// nextWord picks a new word to spell and reads it out.
+func (s *spellingScene) nextWord() {
+	s.word = s.g.assets.Words[s.g.rng.Intn(len(s.g.assets.Words))]
+	s.reshuffle()
+	s.g.say(wordSound(s.word))
+}
+
+// reshuffle scatters the current word's letters again and starts it over.
+func (s *spellingScene) reshuffle() {
+	s.letters = s.scatter()
+	s.nextLetterIdx = 0
+	s.space = collide.NewSpace(cellSize)
+	s.place()
+}
+
+// scatter lays the word's letters out on the arena's "letter" spawn points
+// if it has one for each, or anywhere open otherwise.
+func (s *spellingScene) scatter() []Letter {
+	if at := s.g.spawnPoints("letter", utf8.RuneCountInString(s.word)); at != nil {
+		letters := make([]Letter, 0, len(at))
+		for _, ch := range s.word {
+			letters = append(letters, Letter{char: ch, pos: at[len(letters)]})
+		}
+		return letters
+	}
+	return randomLetterPositions(s.g.rng, s.word, s.g.world, func(p pixel.Vec) bool {
+		return s.g.open(p, s.g.cfg.LetterSize)
+	})
+}
+
+// place puts the letters still on the field into the collision space.
+func (s *spellingScene) place() {
+	for i, l := range s.letters {
+		if !l.collected {
+			s.space.Set(collide.ID(i), pickupBody(l.pos, s.g.cfg.LetterSize))
+		}
+	}
+}
+
+// resize keeps the letters where they were relative to the window.
+func (s *spellingScene) resize(remap func(pixel.Vec) pixel.Vec) {
+	for i := range s.letters {
+		s.letters[i].pos = remap(s.letters[i].pos)
+	}
+	s.place()
+}
+
+func (s *spellingScene) Update(dt float64, in input.State) {
+	s.g.fx.update(dt)
+	s.g.moveUnicorn(dt, in)
+
+	// Back to menu with Escape
+	if in.Back {
+		s.g.toMenu()
+		return
+	}
+	if in.Pause {
+		s.g.scenes.Push(&pauseScene{g: s.g})
+		return
+	}
+	if in.Repeat {
+		s.g.say(wordSound(s.word))
+	}
+
+	for _, id := range s.g.touchPickups(s.space) {
+		i := int(id)
+		if i != s.nextLetterIdx {
+			s.g.cue(soundWrong)
+			s.g.scenes.Push(&tryAgainScene{g: s.g, s: s})
+			return
+		}
+		s.letters[i].collected = true
+		s.space.Remove(id)
+		s.g.sparkle(s.letters[i].pos)
+		s.g.say(letterSound(s.letters[i].char))
+		s.nextLetterIdx++
+		if s.nextLetterIdx >= len(s.letters) {
+			s.g.cue(soundWord)
+			s.g.scenes.Push(&wordCompleteScene{g: s.g, s: s})
+			return
+		}
+		s.g.cue(soundLetter)
+		s.g.react("happy")
+	}
+}
+
+func (s *spellingScene) Draw(t render.Target) {
+	s.g.drawWorld(t, func() {
+		for _, l := range s.letters {
+			if !l.collected {
+				s.g.drawCentered(t, string(l.char), l.pos, 2, colornames.Yellow)
+			}
+		}
+		s.g.drawTrail(t)
+		s.g.drawUnicorn(t)
+		s.g.drawSparkles(t)
+	})
+	s.drawPointer(t)
+
+	// Draw HUD - spelling progress at top
+	hudTxt := text.New(pixel.V(5, s.g.Bounds().Max.Y-15), s.g.atlas)
+	hudTxt.Color = colornames.White
+	hudTxt.WriteString("Spell: ")
+	for i, ch := range []rune(s.word) {
+		if i < s.nextLetterIdx {
+			hudTxt.Color = colornames.Lime
+			hudTxt.WriteRune(ch)
+		} else {
+			hudTxt.Color = colornames.White
+			hudTxt.WriteRune('_')
+		}
+		hudTxt.WriteRune(' ')
+	}
+	hudTxt.Draw(t, pixel.IM)
+
+	// Draw word image prompt
+	if spr, ok := s.g.assets.WordImages[s.word]; ok {
+		imgX := hudTxt.Orig.X + hudTxt.Bounds().W() + 20
+		imgY := s.g.Bounds().Max.Y - 15
+		spr.Draw(t, pixel.IM.Moved(pixel.V(imgX, imgY).Floor()))
+	}
+}
+
+// drawPointer shows an arrow at the edge of the canvas pointing the way to
+// the next letter when it is out of view, with the letter beside it.
+func (s *spellingScene) drawPointer(t render.Target) {
+	if s.nextLetterIdx >= len(s.letters) {
+		return
+	}
+	l := s.letters[s.nextLetterIdx]
+	cam := s.g.view()
+	if cam.Visible().Contains(l.pos) {
+		return
+	}
+
+	// Walk from the middle of the canvas towards the letter until just
+	// inside the edge.
+	const inset = 14.0
+	b := s.g.Bounds()
+	c := b.Center()
+	d := cam.ToScreen(l.pos).Sub(c)
+	scale := math.Inf(1)
+	if d.X != 0 {
+		scale = math.Min(scale, (b.W()/2-inset)/math.Abs(d.X))
+	}
+	if d.Y != 0 {
+		scale = math.Min(scale, (b.H()/2-inset)/math.Abs(d.Y))
+	}
+	dir := d.Unit()
+	tip := c.Add(d.Scaled(scale))
+	side := dir.Normal().Scaled(5)
+	back := tip.Sub(dir.Scaled(9))
+
+	s.g.imd.Clear()
+	s.g.imd.Color = colornames.Yellow
+	s.g.imd.Push(tip, back.Add(side), back.Sub(side))
+	s.g.imd.Polygon(0)
+	s.g.imd.Draw(t)
+	s.g.drawCentered(t, string(l.char), back.Sub(dir.Scaled(8)), 1, colornames.Yellow)
+}
+
+// tryAgainScene is shown over the field after a wrong letter, then scatters
+// the letters for another go.
+type tryAgainScene struct {
+	g     *Game
+	s     *spellingScene
+	timer float64
+}
+
+// Enter has the unicorn look sad for as long as the overlay shows. It can
+// still be steered meanwhile.
+func (ta *tryAgainScene) Enter() { ta.g.hold("sad") }
+
+func (ta *tryAgainScene) Exit()      { ta.g.letGo() }
+func (ta *tryAgainScene) isOverlay() {}
+
+func (ta *tryAgainScene) Update(dt float64, in input.State) {
+	if in.Back {
+		ta.g.toMenu()
+		return
+	}
+	ta.g.fx.update(dt)
+	ta.g.moveUnicorn(dt, in)
+	ta.timer += dt
+	if ta.timer >= ta.g.cfg.TryAgainDelay {
+		ta.s.reshuffle()
+		ta.g.scenes.Pop()
+	}
+}
+
+func (ta *tryAgainScene) Draw(t render.Target) {
+	ta.g.drawDim(t)
+	ta.g.drawCentered(t, "Try Again!", ta.g.Bounds().Center(), 2, colornames.Red)
+}
+
+// wordCompleteScene celebrates a finished word, then moves on to the next.
+type wordCompleteScene struct {
+	g     *Game
+	s     *spellingScene
+	timer float64
+	hue   float64
+}
+
+// Enter has the unicorn celebrate until the next word and throws confetti.
+func (wc *wordCompleteScene) Enter() {
+	wc.g.hold("celebrate")
+	wc.g.throwConfetti()
+}
+
+func (wc *wordCompleteScene) Exit()      { wc.g.letGo() }
+func (wc *wordCompleteScene) isOverlay() {}
+
+func (wc *wordCompleteScene) Update(dt float64, in input.State) {
+	if in.Back {
+		wc.g.toMenu()
+		return
+	}
+	wc.g.fx.update(dt)
+	wc.g.moveUnicorn(dt, in)
+	wc.timer += dt
+	wc.hue = math.Mod(wc.hue+dt*180, 360)
+	if wc.timer >= wc.g.cfg.WordCompleteDelay {
+		wc.s.nextWord()
+		wc.g.scenes.Pop()
+	}
+}
+
+func (wc *wordCompleteScene) Draw(t render.Target) {
+	wc.g.drawDim(t)
+	wc.g.fx.confetti.Draw(t)
+	wc.g.drawCentered(t, wc.s.word, wc.g.Bounds().Center(), 2, hsvToRGB(wc.hue, 1, 1))
+}
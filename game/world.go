@@ -0,0 +1,56 @@
+package game
+
+import (
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/render"
+)
+
+// worldFor returns the bounds of the world for a canvas of the given
+// bounds: the arena's map if there is one, or else the configured world
+// size, grown to at least fill the canvas.
+func (g *Game) worldFor(canvas pixel.Rect) pixel.Rect {
+	if g.arena != nil {
+		return g.arena.m.Bounds()
+	}
+	return pixel.R(0, 0,
+		max(float64(g.cfg.WorldWidth), canvas.W()),
+		max(float64(g.cfg.WorldHeight), canvas.H()),
+	)
+}
+
+// setWorld changes the world's bounds and lays the scenes out again.
+// Whatever is in the world keeps its place relative to the world's edges.
+func (g *Game) setWorld(w pixel.Rect) {
+	old := g.world
+	g.world = w
+	g.cam.World = w
+	remap := func(v pixel.Vec) pixel.Vec { return v }
+	if old != w && old.W() > 0 && old.H() > 0 {
+		remap = func(v pixel.Vec) pixel.Vec {
+			return pixel.V(
+				w.Min.X+(v.X-old.Min.X)*w.W()/old.W(),
+				w.Min.Y+(v.Y-old.Min.Y)*w.H()/old.H(),
+			)
+		}
+	}
+	g.body.Pos = g.clampUnicorn(remap(g.body.Pos))
+	g.prevPos = g.clampUnicorn(remap(g.prevPos))
+	g.scenes.resize(remap)
+}
+
+// view returns the camera as it should be drawn this frame, part way
+// between its last two steps.
+func (g *Game) view() render.Camera {
+	c := g.cam
+	c.Pos = pixel.Lerp(g.prevCam, g.cam.Pos, g.alpha)
+	return c
+}
+
+// drawWorld runs draw with t showing the world through the camera, for
+// things that scroll, then goes back to drawing straight onto the canvas.
+func (g *Game) drawWorld(t render.Target, draw func()) {
+	t.SetMatrix(g.view().Matrix())
+	draw()
+	t.SetMatrix(pixel.IM)
+}
@@ -3,15 +3,20 @@ module unicorn-toots
 go 1.24.0
 
 require (
+	github.com/ebitengine/oto/v3 v3.4.0
 	github.com/gopxl/pixel/v2 v2.3.0
+	github.com/jfreymuth/oggvorbis v1.0.5
 	golang.org/x/image v0.35.0
 )
 
 require (
+	github.com/ebitengine/purego v0.9.0 // indirect
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71 // indirect
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240506104042-037f3cc74f2a // indirect
 	github.com/go-gl/mathgl v1.1.0 // indirect
 	github.com/gopxl/glhf/v2 v2.0.0 // indirect
 	github.com/gopxl/mainthread/v2 v2.1.1 // indirect
+	github.com/jfreymuth/vorbis v1.0.2 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/sys v0.36.0 // indirect
 )
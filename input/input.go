@@ -0,0 +1,198 @@
+// Package input turns devices into per-step control snapshots that the game
+// consumes, so the game never reads a window directly.
+package input
+
+import "github.com/gopxl/pixel/v2"
+
+// State is a snapshot of the player's controls for a single Update step.
+type State struct {
+	Left, Right, Up, Down bool
+	Back                  bool // return to the menu
+	Pause                 bool // pause or resume play
+	Repeat                bool // hear the spoken prompt again
+	Click                 bool // primary mouse button went down this step
+	ClickPos              pixel.Vec
+}
+
+// Merge combines two states, holding a control if either one holds it. The
+// click position comes from whichever state clicked.
+func (s State) Merge(o State) State {
+	m := State{
+		Left:     s.Left || o.Left,
+		Right:    s.Right || o.Right,
+		Up:       s.Up || o.Up,
+		Down:     s.Down || o.Down,
+		Back:     s.Back || o.Back,
+		Pause:    s.Pause || o.Pause,
+		Repeat:   s.Repeat || o.Repeat,
+		Click:    s.Click || o.Click,
+		ClickPos: s.ClickPos,
+	}
+	if o.Click && !s.Click {
+		m.ClickPos = o.ClickPos
+	}
+	return m
+}
+
+// Edges returns only the one-shot controls of s, which fire on the step a
+// button goes down rather than while it is held.
+func (s State) Edges() State {
+	return State{Back: s.Back, Pause: s.Pause, Repeat: s.Repeat, Click: s.Click, ClickPos: s.ClickPos}
+}
+
+// Held returns s with its one-shot controls cleared.
+func (s State) Held() State {
+	return State{Left: s.Left, Right: s.Right, Up: s.Up, Down: s.Down}
+}
+
+// Direction returns the way the movement controls point, as a vector of
+// length 1, or zero when none are held or opposite ones cancel out. Two
+// controls held together point diagonally without being any longer.
+func (s State) Direction() pixel.Vec {
+	var d pixel.Vec
+	if s.Left {
+		d.X--
+	}
+	if s.Right {
+		d.X++
+	}
+	if s.Down {
+		d.Y--
+	}
+	if s.Up {
+		d.Y++
+	}
+	if d == pixel.ZV {
+		return d
+	}
+	return d.Unit()
+}
+
+// Source produces one State per simulation step.
+type Source interface {
+	Poll() State
+}
+
+// SourceFunc adapts a plain function, such as a bot, into a Source.
+type SourceFunc func() State
+
+func (f SourceFunc) Poll() State { return f() }
+
+// Device is the subset of a window's input API that the keyboard and mouse
+// sources read from. *opengl.Window satisfies it.
+type Device interface {
+	Pressed(button pixel.Button) bool
+	JustPressed(button pixel.Button) bool
+	MousePosition() pixel.Vec
+}
+
+// KeyMap lists the keys bound to each control. A control is held if any of
+// its keys is.
+type KeyMap struct {
+	Left, Right, Up, Down, Back, Pause, Repeat []pixel.Button
+}
+
+// DefaultKeys binds the arrow keys and WASD for movement, Escape for back, P
+// for pause, and the space bar or R to repeat the prompt.
+var DefaultKeys = KeyMap{
+	Left:   []pixel.Button{pixel.KeyLeft, pixel.KeyA},
+	Right:  []pixel.Button{pixel.KeyRight, pixel.KeyD},
+	Up:     []pixel.Button{pixel.KeyUp, pixel.KeyW},
+	Down:   []pixel.Button{pixel.KeyDown, pixel.KeyS},
+	Back:   []pixel.Button{pixel.KeyEscape},
+	Pause:  []pixel.Button{pixel.KeyP},
+	Repeat: []pixel.Button{pixel.KeySpace, pixel.KeyR},
+}
+
+// Keyboard reads movement, back, pause and repeat from a device's keys.
+type Keyboard struct {
+	Dev  Device
+	Keys KeyMap
+}
+
+// NewKeyboard returns a keyboard source using DefaultKeys.
+func NewKeyboard(dev Device) *Keyboard {
+	return &Keyboard{Dev: dev, Keys: DefaultKeys}
+}
+
+func (k *Keyboard) Poll() State {
+	return State{
+		Left:   anyPressed(k.Dev, k.Keys.Left),
+		Right:  anyPressed(k.Dev, k.Keys.Right),
+		Up:     anyPressed(k.Dev, k.Keys.Up),
+		Down:   anyPressed(k.Dev, k.Keys.Down),
+		Back:   anyJustPressed(k.Dev, k.Keys.Back),
+		Pause:  anyJustPressed(k.Dev, k.Keys.Pause),
+		Repeat: anyJustPressed(k.Dev, k.Keys.Repeat),
+	}
+}
+
+func anyPressed(dev Device, keys []pixel.Button) bool {
+	for _, k := range keys {
+		if dev.Pressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyJustPressed(dev Device, keys []pixel.Button) bool {
+	for _, k := range keys {
+		if dev.JustPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mouse reports left clicks and where they happened.
+type Mouse struct {
+	Dev Device
+}
+
+func (m *Mouse) Poll() State {
+	if !m.Dev.JustPressed(pixel.MouseButtonLeft) {
+		return State{}
+	}
+	return State{Click: true, ClickPos: m.Dev.MousePosition()}
+}
+
+// Multi merges several sources so any of them can drive the game.
+type Multi []Source
+
+func (ms Multi) Poll() State {
+	var s State
+	for _, src := range ms {
+		s = s.Merge(src.Poll())
+	}
+	return s
+}
+
+// Script plays back a fixed list of states, one per Poll, then reports an
+// idle State once it runs out.
+type Script struct {
+	Steps []State
+	next  int
+}
+
+func (s *Script) Poll() State {
+	if s.next >= len(s.Steps) {
+		return State{}
+	}
+	st := s.Steps[s.next]
+	s.next++
+	return st
+}
+
+// Done reports whether every step has been played.
+func (s *Script) Done() bool { return s.next >= len(s.Steps) }
+
+// Hold returns n copies of st, for building scripts such as "walk right for
+// one second".
+func Hold(st State, n int) []State {
+	steps := make([]State, n)
+	for i := range steps {
+		steps[i] = st
+	}
+	return steps
+}
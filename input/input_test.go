@@ -0,0 +1,112 @@
+package input
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestMerge(t *testing.T) {
+	at := func(x, y float64) State { return State{Click: true, ClickPos: pixel.V(x, y)} }
+	tests := []struct {
+		name string
+		a, b State
+		want State
+	}{
+		{"nothing", State{}, State{}, State{}},
+		{"held from either", State{Left: true}, State{Up: true, Pause: true}, State{Left: true, Up: true, Pause: true}},
+		{"click from the right", State{Right: true}, at(3, 4), State{Right: true, Click: true, ClickPos: pixel.V(3, 4)}},
+		{"click from the left", at(3, 4), State{Down: true}, State{Down: true, Click: true, ClickPos: pixel.V(3, 4)}},
+		{"both clicked", at(1, 2), at(5, 6), at(1, 2)},
+		// A stray position without a click doesn't move the click.
+		{"position without click", at(1, 2), State{ClickPos: pixel.V(9, 9)}, at(1, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Merge(tt.b); got != tt.want {
+				t.Errorf("Merge = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEdgesAndHeld(t *testing.T) {
+	all := State{
+		Left: true, Right: true, Up: true, Down: true,
+		Back: true, Pause: true, Repeat: true, Click: true, ClickPos: pixel.V(7, 8),
+	}
+	edges := State{Back: true, Pause: true, Repeat: true, Click: true, ClickPos: pixel.V(7, 8)}
+	held := State{Left: true, Right: true, Up: true, Down: true}
+	if got := all.Edges(); got != edges {
+		t.Errorf("Edges = %+v, want %+v", got, edges)
+	}
+	if got := all.Held(); got != held {
+		t.Errorf("Held = %+v, want %+v", got, held)
+	}
+	if got := all.Edges().Merge(all.Held()); got != all {
+		t.Errorf("Edges and Held merge back to %+v", got)
+	}
+}
+
+func TestDirection(t *testing.T) {
+	d := 1 / math.Sqrt2
+	tests := []struct {
+		name string
+		in   State
+		want pixel.Vec
+	}{
+		{"none", State{}, pixel.ZV},
+		{"left", State{Left: true}, pixel.V(-1, 0)},
+		{"up", State{Up: true}, pixel.V(0, 1)},
+		{"up right", State{Up: true, Right: true}, pixel.V(d, d)},
+		{"down left", State{Down: true, Left: true}, pixel.V(-d, -d)},
+		{"left and right cancel", State{Left: true, Right: true}, pixel.ZV},
+		{"all four cancel", State{Left: true, Right: true, Up: true, Down: true}, pixel.ZV},
+		{"three held", State{Left: true, Right: true, Down: true}, pixel.V(0, -1)},
+		{"one-shots ignored", State{Pause: true, Click: true}, pixel.ZV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in.Direction()
+			if math.Abs(got.X-tt.want.X) > 1e-12 || math.Abs(got.Y-tt.want.Y) > 1e-12 {
+				t.Errorf("Direction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	walk := State{Right: true}
+	s := &Script{Steps: append(Hold(walk, 2), State{Repeat: true})}
+	want := []State{walk, walk, {Repeat: true}, {}, {}}
+	for i, w := range want {
+		if s.Done() != (i >= 3) {
+			t.Errorf("before poll %d Done = %v", i, s.Done())
+		}
+		if got := s.Poll(); got != w {
+			t.Errorf("poll %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if len(Hold(walk, 0)) != 0 {
+		t.Error("Hold(st, 0) isn't empty")
+	}
+}
+
+func TestMulti(t *testing.T) {
+	n := 0
+	bot := SourceFunc(func() State {
+		n++
+		return State{Left: true}
+	})
+	m := Multi{&Script{Steps: []State{{Click: true, ClickPos: pixel.V(1, 1)}}}, bot}
+	if got, want := m.Poll(), (State{Left: true, Click: true, ClickPos: pixel.V(1, 1)}); got != want {
+		t.Errorf("first poll %+v, want %+v", got, want)
+	}
+	if got, want := m.Poll(), (State{Left: true}); got != want {
+		t.Errorf("second poll %+v, want %+v", got, want)
+	}
+	if n != 2 {
+		t.Errorf("bot polled %d times, want 2", n)
+	}
+}
@@ -0,0 +1,73 @@
+// Package motion moves things with momentum: they speed up towards the
+// direction being steered, glide to a stop when let go, and never go faster
+// than a top speed, whichever way they head.
+package motion
+
+import "github.com/gopxl/pixel/v2"
+
+// rampTime is how long, in seconds, a body with a floatiness of 1 takes to
+// reach top speed from rest, and to glide back to rest.
+const rampTime = 1.0
+
+// Params tunes how a body moves. Speeds are in pixels per second and rates
+// in pixels per second per second.
+type Params struct {
+	MaxSpeed float64 // top speed in any direction
+	Accel    float64 // how quickly steering changes the velocity; 0 is instant
+	Friction float64 // how quickly an unsteered body slows; 0 is instant
+}
+
+// Tuned returns params with the given top speed whose feel is set by a
+// single floatiness between 0 and 1. At 0 a body starts and stops
+// instantly; at 1 it takes a second to get up to speed and as long again to
+// coast to a stop.
+func Tuned(maxSpeed, floatiness float64) Params {
+	if floatiness <= 0 {
+		return Params{MaxSpeed: maxSpeed}
+	}
+	rate := maxSpeed / (floatiness * rampTime)
+	return Params{MaxSpeed: maxSpeed, Accel: rate, Friction: rate}
+}
+
+// Body is something that moves with momentum.
+type Body struct {
+	Pos pixel.Vec
+	Vel pixel.Vec
+}
+
+// Step advances b by dt seconds while it is steered in dir, whose length is
+// how hard it is steered, from 0 for not at all to 1 for full speed. Longer
+// directions are treated as 1, so heading diagonally is no faster than
+// heading straight.
+func (b *Body) Step(p Params, dir pixel.Vec, dt float64) {
+	if l := dir.Len(); l > 1 {
+		dir = dir.Scaled(1 / l)
+	}
+	target := dir.Scaled(p.MaxSpeed)
+	rate := p.Accel
+	if dir == pixel.ZV {
+		rate = p.Friction
+	}
+	b.Vel = approach(b.Vel, target, rate*dt)
+	if l := b.Vel.Len(); l > p.MaxSpeed {
+		b.Vel = b.Vel.Scaled(p.MaxSpeed / l)
+	}
+	b.Pos = b.Pos.Add(b.Vel.Scaled(dt))
+}
+
+// Moving reports whether b has any speed left.
+func (b *Body) Moving() bool { return b.Vel != pixel.ZV }
+
+// Stop takes away all of b's speed.
+func (b *Body) Stop() { b.Vel = pixel.ZV }
+
+// approach moves v towards target by at most step, landing on it exactly
+// when it is close enough. A step of 0 means no limit.
+func approach(v, target pixel.Vec, step float64) pixel.Vec {
+	d := target.Sub(v)
+	l := d.Len()
+	if step <= 0 || l <= step {
+		return target
+	}
+	return v.Add(d.Scaled(step / l))
+}
@@ -0,0 +1,129 @@
+// Package particle animates short-lived specks such as trails, sparkles and
+// confetti. Particles come from a fixed pool, so effects never allocate
+// while the game is running, and each system draws all of its particles in
+// one batch.
+package particle
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Range is a span of values a particle picks from at random when it is
+// emitted.
+type Range struct{ Min, Max float64 }
+
+func (r Range) pick(rng *rand.Rand) float64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rng.Float64()*(r.Max-r.Min)
+}
+
+// Gradient is a color that changes over a particle's life. Its stops are
+// spread evenly from birth to death.
+type Gradient []pixel.RGBA
+
+// Colors returns a gradient through cs.
+func Colors(cs ...color.Color) Gradient {
+	g := make(Gradient, len(cs))
+	for i, c := range cs {
+		g[i] = pixel.ToRGBA(c)
+	}
+	return g
+}
+
+// At returns the color a fraction t of the way through a particle's life.
+func (g Gradient) At(t float64) pixel.RGBA {
+	switch {
+	case len(g) == 0:
+		return pixel.Alpha(1)
+	case len(g) == 1 || t <= 0:
+		return g[0]
+	case t >= 1:
+		return g[len(g)-1]
+	}
+	f := t * float64(len(g)-1)
+	i := int(f)
+	f -= float64(i)
+	return g[i].Scaled(1 - f).Add(g[i+1].Scaled(f))
+}
+
+// Emitter describes the particles of one effect and where they head. Angles
+// are in radians, speeds in pixels per second and times in seconds.
+type Emitter struct {
+	Rate float64 // particles per second while flowing
+
+	Life  Range
+	Speed Range
+
+	// Size is how wide a particle is when it is emitted. Framed particles
+	// are drawn at the nearest whole multiple of their frame.
+	Size Range
+
+	// EndSize is a particle's size when it dies, as a fraction of the size
+	// it was emitted at. 0 is treated as 1, keeping the size.
+	EndSize float64
+
+	Angle  float64   // the direction particles are launched in
+	Spread float64   // how far either side of Angle they may go; π is all round
+	Area   pixel.Vec // particles start anywhere in a box this size around the emission point
+
+	Gravity pixel.Vec // pull, in pixels per second per second
+	Drag    float64   // fraction of its speed a particle loses each second
+
+	// Colors are handed out to particles in turn. With none, particles are
+	// white.
+	Colors []Gradient
+
+	// Frame is the part of the system's picture each particle is drawn
+	// with. Particles of an emitter without one are drawn as squares.
+	Frame pixel.Rect
+
+	next int     // the next of Colors to hand out
+	owed float64 // fraction of a particle due from the last flow
+}
+
+// speck is one particle in flight.
+type speck struct {
+	pos   pixel.Vec
+	vel   pixel.Vec
+	age   float64 // seconds since it was emitted
+	life  float64 // seconds it lasts
+	start float64 // size it was emitted at
+
+	colors Gradient
+	e      *Emitter
+}
+
+// progress is how far through its life p is, from 0 to 1.
+func (p *speck) progress() float64 { return p.age / p.life }
+
+// size is how big p is now.
+func (p *speck) size() float64 {
+	end := p.e.EndSize
+	if end == 0 {
+		end = 1
+	}
+	return p.start * (1 + (end-1)*p.progress())
+}
+
+// emit fills in a new particle from e at at.
+func (e *Emitter) emit(rng *rand.Rand, at pixel.Vec) speck {
+	p := speck{
+		pos:   at.Add(pixel.V((rng.Float64()-0.5)*e.Area.X, (rng.Float64()-0.5)*e.Area.Y)),
+		life:  math.Max(e.Life.pick(rng), 1e-3),
+		start: e.Size.pick(rng),
+		e:     e,
+	}
+	angle := e.Angle + (rng.Float64()*2-1)*e.Spread
+	p.vel = pixel.Unit(angle).Scaled(e.Speed.pick(rng))
+	if len(e.Colors) > 0 {
+		p.colors = e.Colors[e.next%len(e.Colors)]
+		e.next++
+	}
+	return p
+}
@@ -0,0 +1,126 @@
+package particle
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func newSystem(capacity int) *System {
+	return New(capacity, nil, rand.New(rand.NewSource(1)))
+}
+
+func TestPoolCapacity(t *testing.T) {
+	s := newSystem(10)
+	e := &Emitter{Life: Range{1, 1}}
+	s.Burst(e, pixel.ZV, 6)
+	if s.Len() != 6 {
+		t.Fatalf("%d alive, want 6", s.Len())
+	}
+	s.Burst(e, pixel.ZV, 6)
+	if s.Len() != 10 {
+		t.Errorf("%d alive after overfilling, want the pool's 10", s.Len())
+	}
+	pool := &s.parts[0]
+	s.Clear()
+	s.Burst(e, pixel.ZV, 20)
+	if s.Len() != 10 || &s.parts[0] != pool {
+		t.Errorf("%d alive after clearing and overfilling; the pool should be reused", s.Len())
+	}
+}
+
+func TestFlowRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		dts  []float64
+		want []int // particles alive after each flow
+	}{
+		{"whole particles", 60, []float64{0.05, 0.05}, []int{3, 6}},
+		{"fractions carried", 10, []float64{0.05, 0.05, 0.05, 0.05}, []int{0, 1, 1, 2}},
+		{"slow", 1, []float64{0.4, 0.4, 0.4}, []int{0, 0, 1}},
+		{"stopped", 30, []float64{0, 0}, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSystem(100)
+			e := &Emitter{Rate: tt.rate, Life: Range{10, 10}}
+			for i, dt := range tt.dts {
+				s.Flow(e, pixel.ZV, pixel.ZV, dt)
+				if s.Len() != tt.want[i] {
+					t.Errorf("flow %d: %d alive, want %d", i, s.Len(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFlowSpread(t *testing.T) {
+	// Particles flowing from a moving emitter are laid along its path, the
+	// last at where it got to.
+	s := newSystem(10)
+	e := &Emitter{Rate: 40, Life: Range{1, 1}}
+	s.Flow(e, pixel.V(0, 0), pixel.V(40, 20), 0.1)
+	if s.Len() != 4 {
+		t.Fatalf("%d alive, want 4", s.Len())
+	}
+	for i, want := range []pixel.Vec{pixel.V(10, 5), pixel.V(20, 10), pixel.V(30, 15), pixel.V(40, 20)} {
+		if got := s.parts[i].pos; got != want {
+			t.Errorf("particle %d at %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	s := newSystem(10)
+	short := &Emitter{Life: Range{0.5, 0.5}}
+	long := &Emitter{Life: Range{1, 1}}
+	s.Burst(short, pixel.ZV, 3)
+	s.Burst(long, pixel.ZV, 2)
+
+	steps := []struct {
+		dt   float64
+		want int
+	}{
+		{0.25, 5},
+		{0.25, 2}, // the short-lived ones die at exactly their life
+		{0.4, 2},
+		{0.1, 0},
+		{1, 0},
+	}
+	for i, st := range steps {
+		s.Update(st.dt)
+		if s.Len() != st.want {
+			t.Errorf("step %d: %d alive, want %d", i, s.Len(), st.want)
+		}
+		if i == 0 {
+			continue
+		}
+		for _, p := range s.parts[:s.Len()] {
+			if p.e != long {
+				t.Errorf("step %d: a short-lived particle outlived its life", i)
+			}
+		}
+	}
+}
+
+func TestMotion(t *testing.T) {
+	s := newSystem(1)
+	e := &Emitter{Life: Range{10, 10}, Speed: Range{10, 10}, Gravity: pixel.V(0, -20), Drag: 0.5}
+	s.Burst(e, pixel.V(5, 5), 1)
+	p := &s.parts[0]
+	if math.Abs(p.vel.Len()-10) > 1e-9 || p.vel.Y != 0 {
+		t.Fatalf("launched at %v, want 10 to the right", p.vel)
+	}
+	s.Update(0.1)
+	// Gravity pulls first, then drag slows the result by 5%.
+	want := pixel.V(10, -2).Scaled(0.95)
+	if p.vel.Sub(want).Len() > 1e-9 {
+		t.Errorf("velocity %v, want %v", p.vel, want)
+	}
+	if at := pixel.V(5, 5).Add(want.Scaled(0.1)); p.pos.Sub(at).Len() > 1e-9 {
+		t.Errorf("at %v, want %v", p.pos, at)
+	}
+}
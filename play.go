@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+	"golang.org/x/image/colornames"
+
+	"unicorn-toots/assets"
+	"unicorn-toots/audio"
+	"unicorn-toots/audio/speaker"
+	"unicorn-toots/config"
+	"unicorn-toots/game"
+	"unicorn-toots/input"
+	"unicorn-toots/render"
+	"unicorn-toots/replay"
+)
+
+// session is everything play needs to know before it opens the window.
+type session struct {
+	src        sources
+	conf       config.Config
+	mode       game.Mode
+	seed       int64
+	fullscreen bool
+	debug      bool
+
+	recordPath string
+	playback   *replay.Recording // replay to play back, if any
+}
+
+// playCmd opens the game window.
+func playCmd(args []string) error {
+	fs := newFlagSet("play", "")
+	var s session
+	s.src.register(fs)
+	modeName := fs.String("mode", "menu", "start in menu, spelling or gem mode")
+	fs.Int64Var(&s.seed, "seed", 0, "seed for all game randomness (0 picks one from the clock)")
+	fs.BoolVar(&s.fullscreen, "fullscreen", false, "start fullscreen (F11 toggles it while playing)")
+	fs.BoolVar(&s.debug, "debug", false, "show the debug overlay")
+	fs.StringVar(&s.recordPath, "record", "", "record the session's input to this replay file")
+	replayPath := fs.String("replay", "", "play back a replay file")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("play: unexpected argument %q", fs.Arg(0))
+	}
+
+	var err error
+	if s.mode, err = game.ParseMode(*modeName); err != nil {
+		return err
+	}
+	if s.conf, err = s.src.loadConfig(); err != nil {
+		return err
+	}
+
+	// A replay supplies the seed, starting mode and every step's input
+	// until it runs out.
+	if *replayPath != "" {
+		if s.playback, err = replay.Load(*replayPath); err != nil {
+			return err
+		}
+		s.seed = s.playback.Seed
+		s.mode = game.Mode(s.playback.Start)
+	}
+	if s.seed == 0 {
+		s.seed = time.Now().UnixNano()
+	}
+
+	opengl.Run(func() { err = s.run() })
+	return err
+}
+
+func (s *session) run() error {
+	// Replays only line up with the canvas size they were recorded at. A
+	// letterboxed canvas never changes size, but one that grows with the
+	// window means the window has to stay put while recording or playing back.
+	fixedSize := !s.conf.Letterbox && (s.recordPath != "" || s.playback != nil)
+
+	cfg := opengl.WindowConfig{
+		Title:     "Unicorn Toots",
+		Bounds:    pixel.R(0, 0, float64(s.conf.WindowWidth), float64(s.conf.WindowHeight)),
+		VSync:     true,
+		Resizable: !fixedSize,
+	}
+	win, err := opengl.NewWindow(cfg)
+	if err != nil {
+		return err
+	}
+	if s.fullscreen && !fixedSize {
+		toggleFullscreen(win)
+	}
+
+	// The game draws on a low-resolution canvas that is blown up to the
+	// window at a whole-number scale.
+	canvasSize := pixel.V(float64(s.conf.CanvasWidth), float64(s.conf.CanvasHeight))
+	canvas := opengl.NewCanvas(pixel.R(0, 0, canvasSize.X, canvasSize.Y))
+
+	loop := game.NewLoop()
+
+	var player *replay.Player
+	var diverged error
+	if s.playback != nil {
+		loop.Step = 1 / float64(s.playback.TickRate)
+		player = s.playback.Player()
+	}
+
+	fmt.Println("Seed:", s.seed)
+
+	m := s.src.manager()
+	a := loadAssets(m)
+	g := game.New(a, s.conf, s.seed)
+	g.Debug = s.debug
+	g.Start(s.mode)
+
+	// Sound is a nicety: without a sound device the game plays silently.
+	mixer := audio.NewMixer(speaker.Rate, a.SoundBank())
+	mixer.SetVolume(audio.Effects, s.conf.EffectsVolume)
+	mixer.SetVolume(audio.Speech, s.conf.SpeechVolume)
+	if spk, err := speaker.Open(mixer); err != nil {
+		fmt.Println("No sound:", err)
+	} else {
+		defer spk.Close()
+		g.SetAudio(mixer)
+	}
+
+	// Pick up edits to the word list, word images and recordings on disk
+	// while running; the built-in ones never change. Not while recording or
+	// playing back, though: new words change which ones get picked, so the
+	// session would no longer match its replay.
+	var reloads <-chan assets.Reload
+	if s.src.onDisk() && s.recordPath == "" && s.playback == nil {
+		watcher := assets.NewWatcher(m, "words.txt", "words", time.Second)
+		defer watcher.Stop()
+		reloads = watcher.Updates()
+	}
+
+	var rec *replay.Recording
+	if s.recordPath != "" {
+		rec = replay.New(s.seed, game.TickRate)
+		rec.Start = int(s.mode)
+	}
+
+	controls := input.Multi{input.NewKeyboard(win), &input.Mouse{Dev: win}}
+
+	last := time.Now()
+	for !win.Closed() {
+		dt := time.Since(last).Seconds()
+		last = time.Now()
+
+		if !fixedSize && win.JustPressed(pixel.KeyF11) {
+			toggleFullscreen(win)
+		}
+		vp := render.Fit(win.Bounds(), canvasSize, s.conf.Letterbox)
+		if canvas.Bounds() != vp.Canvas {
+			canvas.SetBounds(vp.Canvas)
+		}
+		g.Resize(vp.Canvas)
+
+		select {
+		case r := <-reloads:
+			g.ApplyReload(r)
+			if r.WordSounds != nil {
+				mixer.SetSounds(g.SoundBank())
+			}
+		default:
+		}
+
+		polled := controls.Poll()
+		polled.ClickPos = vp.ToCanvas(polled.ClickPos)
+		loop.Advance(dt, polled, func(in input.State) {
+			fromReplay := player != nil && !player.Done()
+			if fromReplay {
+				in = player.Poll()
+			}
+			g.Update(loop.Step, in)
+
+			// Keep playing after a divergence so it can be watched; play
+			// fails with it once the window is closed.
+			if fromReplay && diverged == nil {
+				diverged = s.playback.Verify(player.Tick(), g.Checksum())
+			}
+			if rec != nil {
+				rec.Add(in)
+				if rec.Due() {
+					rec.AddCheck(g.Checksum())
+				}
+			}
+		})
+		g.SetAlpha(loop.Alpha())
+		g.Draw(canvas)
+		win.Clear(colornames.Black)
+		canvas.Draw(win, vp.Matrix())
+		win.Update()
+	}
+
+	if rec != nil {
+		// Checks are only due every so often, so end on one to cover the
+		// steps since the last.
+		if len(rec.Steps) > 0 && !rec.Due() {
+			rec.AddCheck(g.Checksum())
+		}
+		if err := rec.Save(s.recordPath); err != nil {
+			return fmt.Errorf("could not save recording: %w", err)
+		}
+	}
+	return diverged
+}
+
+// toggleFullscreen switches the window between fullscreen on the primary
+// monitor and its previous windowed size.
+func toggleFullscreen(win *opengl.Window) {
+	if win.Monitor() != nil {
+		win.SetMonitor(nil)
+	} else {
+		win.SetMonitor(opengl.PrimaryMonitor())
+	}
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Camera shows part of a world larger than the canvas. It trails a target
+// loosely: the target can wander a dead zone in the middle of the view
+// without the camera moving, and once it leaves, the camera glides after it
+// rather than jumping.
+type Camera struct {
+	Pos   pixel.Vec  // world position shown at the centre of the view
+	View  pixel.Vec  // size of the view, in canvas pixels
+	World pixel.Rect // the camera never shows beyond this
+
+	// DeadZone is the size of the box in the middle of the view, as a
+	// fraction of the view, that the target moves freely within.
+	DeadZone float64
+	// Lag is roughly how many seconds the camera takes to catch up with a
+	// target that left the dead zone. 0 keeps it exactly at the edge.
+	Lag float64
+}
+
+// Follow moves the camera dt seconds towards keeping target inside the
+// dead zone.
+func (c *Camera) Follow(target pixel.Vec, dt float64) {
+	half := c.View.Scaled(c.DeadZone / 2)
+	want := c.Pos
+	want.X = math.Max(target.X-half.X, math.Min(want.X, target.X+half.X))
+	want.Y = math.Max(target.Y-half.Y, math.Min(want.Y, target.Y+half.Y))
+	if c.Lag > 0 {
+		want = pixel.Lerp(c.Pos, want, 1-math.Exp(-dt/c.Lag))
+	}
+	c.Pos = c.clamp(want)
+}
+
+// Snap centres the camera on target straight away, as far as the world's
+// edges allow.
+func (c *Camera) Snap(target pixel.Vec) { c.Pos = c.clamp(target) }
+
+// clamp keeps the view inside the world, or centred on it along any axis
+// where the world is smaller than the view.
+func (c *Camera) clamp(p pixel.Vec) pixel.Vec {
+	axis := func(v, lo, hi, view float64) float64 {
+		if hi-lo <= view {
+			return (lo + hi) / 2
+		}
+		return math.Max(lo+view/2, math.Min(v, hi-view/2))
+	}
+	return pixel.V(
+		axis(p.X, c.World.Min.X, c.World.Max.X, c.View.X),
+		axis(p.Y, c.World.Min.Y, c.World.Max.Y, c.View.Y),
+	)
+}
+
+// offset is how far the world is moved to put Pos in the middle of the
+// view, kept to whole pixels so pixel art stays crisp while scrolling.
+func (c Camera) offset() pixel.Vec { return c.View.Scaled(0.5).Sub(c.Pos).Floor() }
+
+// Matrix maps world positions onto the canvas.
+func (c Camera) Matrix() pixel.Matrix { return pixel.IM.Moved(c.offset()) }
+
+// ToScreen returns where the world position p appears on the canvas.
+func (c Camera) ToScreen(p pixel.Vec) pixel.Vec { return p.Add(c.offset()) }
+
+// ToWorld returns the world position under the canvas position p.
+func (c Camera) ToWorld(p pixel.Vec) pixel.Vec { return p.Sub(c.offset()) }
+
+// Visible returns the area of the world in view.
+func (c Camera) Visible() pixel.Rect {
+	return pixel.Rect{Max: c.View}.Moved(c.offset().Scaled(-1))
+}
@@ -0,0 +1,128 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// camera returns a 100x80 view of a 400x300 world, centred at (200, 150),
+// with a dead zone half the view and no lag.
+func camera() Camera {
+	return Camera{
+		Pos:      pixel.V(200, 150),
+		View:     pixel.V(100, 80),
+		World:    pixel.R(0, 0, 400, 300),
+		DeadZone: 0.5,
+	}
+}
+
+func TestCameraFollow(t *testing.T) {
+	tests := []struct {
+		name   string
+		target pixel.Vec
+		want   pixel.Vec
+	}{
+		{"in the dead zone", pixel.V(220, 165), pixel.V(200, 150)},
+		{"on its edge", pixel.V(225, 130), pixel.V(200, 150)},
+		{"past the right", pixel.V(240, 150), pixel.V(215, 150)},
+		{"past the bottom left", pixel.V(150, 100), pixel.V(175, 120)},
+		{"near the world's corner", pixel.V(395, 295), pixel.V(350, 260)},
+		{"off the world", pixel.V(-50, 150), pixel.V(50, 150)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := camera()
+			c.Follow(tt.target, 1.0/60)
+			if c.Pos != tt.want {
+				t.Errorf("camera at %v, want %v", c.Pos, tt.want)
+			}
+		})
+	}
+}
+
+func TestCameraLag(t *testing.T) {
+	c := camera()
+	c.Lag = 0.25
+	target := pixel.V(300, 150) // the dead zone's edge wants the camera at 275
+	c.Follow(target, 1.0/60)
+	if c.Pos.X <= 200 || c.Pos.X >= 275 {
+		t.Fatalf("after one step the camera is at %v, want part way to 275", c.Pos)
+	}
+	for i := 0; i < 5*60; i++ {
+		c.Follow(target, 1.0/60)
+	}
+	if math.Abs(c.Pos.X-275) > 1e-6 || c.Pos.Y != 150 {
+		t.Errorf("after five seconds the camera is at %v, want (275, 150)", c.Pos)
+	}
+}
+
+func TestCameraClamp(t *testing.T) {
+	tests := []struct {
+		name   string
+		world  pixel.Rect
+		target pixel.Vec
+		want   pixel.Vec
+	}{
+		{"inside", pixel.R(0, 0, 400, 300), pixel.V(120, 200), pixel.V(120, 200)},
+		{"low corner", pixel.R(0, 0, 400, 300), pixel.V(10, 10), pixel.V(50, 40)},
+		{"high corner", pixel.R(0, 0, 400, 300), pixel.V(400, 300), pixel.V(350, 260)},
+		{"offset world", pixel.R(-200, 100, 0, 400), pixel.V(0, 0), pixel.V(-50, 140)},
+		{"narrow world", pixel.R(0, 0, 60, 300), pixel.V(5, 200), pixel.V(30, 200)},
+		{"small world", pixel.R(0, 0, 100, 80), pixel.V(90, 10), pixel.V(50, 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := camera()
+			c.World = tt.world
+			c.Snap(tt.target)
+			if c.Pos != tt.want {
+				t.Errorf("Snap(%v) put the camera at %v, want %v", tt.target, c.Pos, tt.want)
+			}
+			// Follow clamps the same way however far it moves.
+			c.Pos = pixel.ZV
+			c.DeadZone = 0
+			c.Follow(tt.target, 1.0/60)
+			if c.Pos != tt.want {
+				t.Errorf("Follow(%v) put the camera at %v, want %v", tt.target, c.Pos, tt.want)
+			}
+		})
+	}
+}
+
+func TestCameraMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  pixel.Vec
+	}{
+		{"centred", pixel.V(200, 150)},
+		{"between pixels", pixel.V(200.6, 149.3)},
+		{"at the world's edge", pixel.V(50, 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := camera()
+			c.Pos = tt.pos
+			if got := c.ToScreen(c.Pos); got.Sub(pixel.V(50, 40)).Len() >= math.Sqrt2 {
+				t.Errorf("the camera's position shows at %v, want the middle of the view", got)
+			}
+			for _, p := range []pixel.Vec{pixel.ZV, pixel.V(123.5, 67.25), pixel.V(400, 300)} {
+				if got := c.ToWorld(c.ToScreen(p)); got != p {
+					t.Errorf("%v went round to %v", p, got)
+				}
+				if got := c.Matrix().Project(p); got != c.ToScreen(p) {
+					t.Errorf("Matrix puts %v at %v, ToScreen at %v", p, got, c.ToScreen(p))
+				}
+			}
+			// The world moves by whole pixels, so pixel art stays crisp.
+			if off := c.ToScreen(pixel.ZV); off != off.Floor() {
+				t.Errorf("world offset %v isn't whole pixels", off)
+			}
+			vis := c.Visible()
+			if vis.Size() != c.View || c.ToScreen(vis.Min) != pixel.ZV {
+				t.Errorf("visible %v for a view of %v", vis, c.View)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Image is a software Target that rasterizes pixel triangles into an
+// image.RGBA. Textures are sampled with nearest-neighbor filtering, which
+// matches the OpenGL backend with smoothing off.
+type Image struct {
+	rgba   *image.RGBA
+	bounds pixel.Rect
+	mat    pixel.Matrix
+	mask   pixel.RGBA
+	cmp    pixel.ComposeMethod
+}
+
+// NewImage creates a transparent software target covering bounds. The bounds
+// are rounded to whole pixels.
+func NewImage(bounds pixel.Rect) *Image {
+	bounds = pixel.R(
+		math.Floor(bounds.Min.X), math.Floor(bounds.Min.Y),
+		math.Ceil(bounds.Max.X), math.Ceil(bounds.Max.Y),
+	)
+	return &Image{
+		rgba:   image.NewRGBA(image.Rect(0, 0, int(bounds.W()), int(bounds.H()))),
+		bounds: bounds,
+		mat:    pixel.IM,
+		mask:   pixel.Alpha(1),
+	}
+}
+
+// Bounds returns the area of the target in pixel coordinates.
+func (im *Image) Bounds() pixel.Rect {
+	return im.bounds
+}
+
+// RGBA returns the rendered frame. Row 0 is the top of the target, as usual
+// for images, while pixel coordinates grow upward.
+func (im *Image) RGBA() *image.RGBA {
+	return im.rgba
+}
+
+// Clear fills the whole target with c.
+func (im *Image) Clear(c color.Color) {
+	r, g, b, a := c.RGBA()
+	px := color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	for i := 0; i < len(im.rgba.Pix); i += 4 {
+		im.rgba.Pix[i+0] = px.R
+		im.rgba.Pix[i+1] = px.G
+		im.rgba.Pix[i+2] = px.B
+		im.rgba.Pix[i+3] = px.A
+	}
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (im *Image) SetMatrix(m pixel.Matrix) {
+	im.mat = m
+}
+
+// SetColorMask sets a color that every drawn pixel is multiplied by.
+func (im *Image) SetColorMask(c color.Color) {
+	if c == nil {
+		im.mask = pixel.Alpha(1)
+		return
+	}
+	im.mask = pixel.ToRGBA(c)
+}
+
+// SetComposeMethod sets how drawn pixels are blended with the existing ones.
+func (im *Image) SetComposeMethod(cmp pixel.ComposeMethod) {
+	im.cmp = cmp
+}
+
+// MakeTriangles returns a copy of t that draws onto this Image.
+func (im *Image) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	data := pixel.MakeTrianglesData(t.Len())
+	data.Update(t)
+	return &imageTriangles{data: data, dst: im}
+}
+
+// MakePicture returns a copy of p that draws onto this Image. Pictures that
+// don't report colors (pixel.PictureColor) draw as opaque white.
+func (im *Image) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return &imagePicture{pic: p, dst: im}
+}
+
+type imageTriangles struct {
+	data *pixel.TrianglesData
+	dst  *Image
+}
+
+func (it *imageTriangles) Len() int {
+	return it.data.Len()
+}
+
+func (it *imageTriangles) SetLen(n int) {
+	it.data.SetLen(n)
+}
+
+func (it *imageTriangles) Slice(i, j int) pixel.Triangles {
+	return &imageTriangles{data: it.data.Slice(i, j).(*pixel.TrianglesData), dst: it.dst}
+}
+
+func (it *imageTriangles) Update(t pixel.Triangles) {
+	if t.Len() != it.Len() {
+		panic(fmt.Errorf("(%T).Update: invalid triangles len", it))
+	}
+	it.data.Update(t)
+}
+
+func (it *imageTriangles) Copy() pixel.Triangles {
+	return &imageTriangles{data: it.data.Copy().(*pixel.TrianglesData), dst: it.dst}
+}
+
+func (it *imageTriangles) Draw() {
+	it.dst.fill(it.data, nil)
+}
+
+type imagePicture struct {
+	pic pixel.Picture
+	dst *Image
+}
+
+func (ip *imagePicture) Bounds() pixel.Rect {
+	return ip.pic.Bounds()
+}
+
+func (ip *imagePicture) Draw(t pixel.TargetTriangles) {
+	it, ok := t.(*imageTriangles)
+	if !ok || it.dst != ip.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Target", ip))
+	}
+	ip.dst.fill(it.data, ip.pic)
+}
+
+// fill rasterizes every triangle in data, sampling pic where the vertices ask
+// for it. It mirrors the OpenGL backend's fragment shader.
+func (im *Image) fill(data *pixel.TrianglesData, pic pixel.Picture) {
+	colors, _ := pic.(pixel.PictureColor)
+	tris := *data
+	for i := 0; i+2 < len(tris); i += 3 {
+		im.fillTriangle(tris[i:i+3], pic, colors)
+	}
+}
+
+func (im *Image) fillTriangle(tri pixel.TrianglesData, pic pixel.Picture, colors pixel.PictureColor) {
+	a, b, c := &tri[0], &tri[1], &tri[2]
+	pa := im.mat.Project(a.Position).Sub(im.bounds.Min)
+	pb := im.mat.Project(b.Position).Sub(im.bounds.Min)
+	pc := im.mat.Project(c.Position).Sub(im.bounds.Min)
+
+	area := edge(pa, pb, pc)
+	if area == 0 {
+		return
+	}
+	// Normalize winding so the edge functions are positive inside.
+	if area < 0 {
+		pb, pc = pc, pb
+		b, c = c, b
+		area = -area
+	}
+
+	w, h := im.rgba.Rect.Dx(), im.rgba.Rect.Dy()
+	x0 := clampInt(int(math.Floor(math.Min(pa.X, math.Min(pb.X, pc.X)))), 0, w-1)
+	x1 := clampInt(int(math.Ceil(math.Max(pa.X, math.Max(pb.X, pc.X)))), 0, w-1)
+	y0 := clampInt(int(math.Floor(math.Min(pa.Y, math.Min(pb.Y, pc.Y)))), 0, h-1)
+	y1 := clampInt(int(math.Ceil(math.Max(pa.Y, math.Max(pb.Y, pc.Y)))), 0, h-1)
+
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			p := pixel.V(float64(x)+0.5, float64(y)+0.5)
+			wa := edge(pb, pc, p)
+			wb := edge(pc, pa, p)
+			wc := edge(pa, pb, p)
+			if !inside(wa, pb, pc) || !inside(wb, pc, pa) || !inside(wc, pa, pb) {
+				continue
+			}
+			wa, wb, wc = wa/area, wb/area, wc/area
+
+			col := a.Color.Scaled(wa).Add(b.Color.Scaled(wb)).Add(c.Color.Scaled(wc))
+			intensity := a.Intensity*wa + b.Intensity*wb + c.Intensity*wc
+
+			var frag pixel.RGBA
+			if pic == nil || intensity == 0 {
+				frag = im.mask.Mul(col)
+			} else {
+				tex := pixel.Alpha(1)
+				if colors != nil {
+					at := a.Picture.Scaled(wa).Add(b.Picture.Scaled(wb)).Add(c.Picture.Scaled(wc))
+					tex = colors.Color(at)
+				}
+				frag = col.Scaled(1 - intensity).Add(col.Mul(tex).Scaled(intensity)).Mul(im.mask)
+			}
+			im.blend(x, h-1-y, frag)
+		}
+	}
+}
+
+func (im *Image) blend(x, y int, src pixel.RGBA) {
+	i := im.rgba.PixOffset(x, y)
+	px := im.rgba.Pix[i : i+4 : i+4]
+	dst := pixel.RGBA{
+		R: float64(px[0]) / 255,
+		G: float64(px[1]) / 255,
+		B: float64(px[2]) / 255,
+		A: float64(px[3]) / 255,
+	}
+	out := im.cmp.Compose(src, dst)
+	px[0] = toByte(out.R)
+	px[1] = toByte(out.G)
+	px[2] = toByte(out.B)
+	px[3] = toByte(out.A)
+}
+
+// edge is twice the signed area of triangle abp; positive when p lies to the
+// left of a->b.
+func edge(a, b, p pixel.Vec) float64 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// inside applies a top-left fill rule so pixels on an edge shared by two
+// triangles are drawn exactly once.
+func inside(w float64, a, b pixel.Vec) bool {
+	if w != 0 {
+		return w > 0
+	}
+	d := b.Sub(a)
+	return d.Y < 0 || (d.Y == 0 && d.X > 0)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func toByte(f float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, f)) * 255))
+}
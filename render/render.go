@@ -0,0 +1,20 @@
+// Package render defines the surface the game draws onto and provides a
+// pure-Go software backend for it, so frames can be produced on machines
+// without a GPU.
+package render
+
+import (
+	"image/color"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Target is anything a frame can be drawn onto. *opengl.Window and
+// *opengl.Canvas satisfy it, as does the software *Image.
+type Target interface {
+	pixel.ComposeTarget
+	Bounds() pixel.Rect
+	Clear(c color.Color)
+}
+
+var _ Target = (*Image)(nil)
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Viewport places a low-resolution canvas in a window at a whole-number
+// scale, so every canvas pixel covers the same square of screen pixels and
+// pixel art stays crisp. Whatever the canvas doesn't cover is left for
+// letterbox bars.
+type Viewport struct {
+	Canvas pixel.Rect // canvas bounds, with the origin at its bottom-left
+	Scale  float64    // screen pixels per canvas pixel, always a whole number
+	Offset pixel.Vec  // window position of the canvas's bottom-left corner
+}
+
+// Fit picks the largest whole-number scale at which a size canvas still fits
+// in win. With letterbox set the canvas stays exactly that size and is
+// centred with bars around it; otherwise it grows in whole canvas pixels to
+// fill as much of the window as that scale allows. A window too small for the
+// canvas gets a scale of 1 and is cropped.
+func Fit(win pixel.Rect, size pixel.Vec, letterbox bool) Viewport {
+	scale := math.Max(1, math.Floor(math.Min(win.W()/size.X, win.H()/size.Y)))
+	if !letterbox {
+		size = pixel.V(math.Floor(win.W()/scale), math.Floor(win.H()/scale))
+	}
+	spare := win.Size().Sub(size.Scaled(scale))
+	return Viewport{
+		Canvas: pixel.R(0, 0, size.X, size.Y),
+		Scale:  scale,
+		Offset: win.Min.Add(pixel.V(math.Floor(spare.X/2), math.Floor(spare.Y/2))),
+	}
+}
+
+// Matrix places a sprite of the whole canvas, such as an *opengl.Canvas
+// drawn with its Draw method, onto the window.
+func (v Viewport) Matrix() pixel.Matrix {
+	return pixel.IM.Scaled(pixel.ZV, v.Scale).Moved(v.Screen().Center())
+}
+
+// Screen is the part of the window the canvas covers.
+func (v Viewport) Screen() pixel.Rect {
+	return pixel.Rect{Min: v.Offset, Max: v.Offset.Add(v.Canvas.Size().Scaled(v.Scale))}
+}
+
+// ToCanvas converts a window position, such as the mouse, to canvas
+// coordinates.
+func (v Viewport) ToCanvas(p pixel.Vec) pixel.Vec {
+	return p.Sub(v.Offset).Scaled(1 / v.Scale)
+}
@@ -0,0 +1,350 @@
+// Package replay records the per-step input of a session, together with the
+// seed it ran with, so the exact session can be played back later.
+//
+// A recording also carries periodic checksums of the game state. Playing it
+// back against a build whose simulation has drifted is reported as a
+// DivergenceError at the first checkpoint that disagrees.
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/game"
+	"unicorn-toots/input"
+)
+
+// CheckInterval is how many steps pass between recorded state checksums.
+const CheckInterval = 60
+
+// Limits on what Read accepts, so a corrupt or hostile file can't ask for
+// more memory than any real session would use.
+const (
+	maxTickRate = 1000
+	maxSteps    = 4 * 60 * 60 * 60 // four hours of play at 60 steps a second
+)
+
+const (
+	magic   = "UTRP"
+	version = 1
+)
+
+// Record tags in the encoded stream.
+const (
+	tagStart = 'S' // the game mode the session started in
+	tagInput = 'I' // a run of identical input states
+	tagCheck = 'C' // a state checksum
+	tagEnd   = 'E'
+)
+
+// Bits of the packed input mask.
+const (
+	bitLeft = 1 << iota
+	bitRight
+	bitUp
+	bitDown
+	bitBack
+	bitPause
+	bitClick
+	bitRepeat
+)
+
+// Check is the game state checksum taken after a given number of steps.
+type Check struct {
+	Tick int
+	Sum  uint64
+}
+
+// Recording is a seed plus the input of every simulation step.
+type Recording struct {
+	Seed     int64
+	TickRate int
+	Start    int // game mode the session started in, as a game.Mode
+	Steps    []input.State
+	Checks   []Check
+}
+
+// New starts an empty recording for a session using seed.
+func New(seed int64, tickRate int) *Recording {
+	return &Recording{Seed: seed, TickRate: tickRate}
+}
+
+// Add appends the input of one step.
+func (r *Recording) Add(in input.State) {
+	r.Steps = append(r.Steps, in)
+}
+
+// Due reports whether a checksum should be taken now that len(Steps) steps
+// have run.
+func (r *Recording) Due() bool {
+	return len(r.Steps) > 0 && len(r.Steps)%CheckInterval == 0
+}
+
+// AddCheck records the state checksum after the steps added so far.
+func (r *Recording) AddCheck(sum uint64) {
+	r.Checks = append(r.Checks, Check{Tick: len(r.Steps), Sum: sum})
+}
+
+// DivergenceError reports a playback whose state no longer matches the
+// recording.
+type DivergenceError struct {
+	Tick      int
+	Want, Got uint64
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("replay diverged at step %d: state checksum %016x, recorded %016x", e.Tick, e.Got, e.Want)
+}
+
+// Verify compares sum, taken after tick steps of playback, against the
+// recorded checksum for that step, if there is one.
+func (r *Recording) Verify(tick int, sum uint64) error {
+	for _, c := range r.Checks {
+		if c.Tick == tick {
+			if c.Sum != sum {
+				return &DivergenceError{Tick: tick, Want: c.Sum, Got: sum}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Player feeds a recording's steps back as an input.Source.
+type Player struct {
+	rec  *Recording
+	next int
+}
+
+// Player returns a source that replays r from the first step.
+func (r *Recording) Player() *Player {
+	return &Player{rec: r}
+}
+
+// Poll returns the next recorded step, or an idle state once playback is
+// done.
+func (p *Player) Poll() input.State {
+	if p.Done() {
+		return input.State{}
+	}
+	in := p.rec.Steps[p.next]
+	p.next++
+	return in
+}
+
+// Tick returns how many steps have been played.
+func (p *Player) Tick() int { return p.next }
+
+// Done reports whether every recorded step has been played.
+func (p *Player) Done() bool { return p.next >= len(p.rec.Steps) }
+
+// Save writes r to path.
+func (r *Recording) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads a recording from path.
+func Load(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Write encodes r. Runs of identical steps are stored once with a count, so
+// a held key costs a few bytes however long it is held.
+func (r *Recording) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		bw.Write(buf[:binary.PutUvarint(buf[:], v)])
+	}
+
+	bw.WriteString(magic)
+	bw.WriteByte(version)
+	bw.Write(buf[:binary.PutVarint(buf[:], r.Seed)])
+	putUvarint(uint64(r.TickRate))
+
+	if r.Start != 0 {
+		bw.WriteByte(tagStart)
+		putUvarint(uint64(r.Start))
+	}
+	for i := 0; i < len(r.Steps); {
+		run := 1
+		for i+run < len(r.Steps) && r.Steps[i+run] == r.Steps[i] {
+			run++
+		}
+		bw.WriteByte(tagInput)
+		putUvarint(uint64(run))
+		writeState(bw, r.Steps[i])
+		i += run
+	}
+	for _, c := range r.Checks {
+		bw.WriteByte(tagCheck)
+		putUvarint(uint64(c.Tick))
+		binary.Write(bw, binary.LittleEndian, c.Sum)
+	}
+	bw.WriteByte(tagEnd)
+	return bw.Flush()
+}
+
+func writeState(w *bufio.Writer, s input.State) {
+	var mask byte
+	set := func(bit byte, on bool) {
+		if on {
+			mask |= bit
+		}
+	}
+	set(bitLeft, s.Left)
+	set(bitRight, s.Right)
+	set(bitUp, s.Up)
+	set(bitDown, s.Down)
+	set(bitBack, s.Back)
+	set(bitPause, s.Pause)
+	set(bitClick, s.Click)
+	set(bitRepeat, s.Repeat)
+	w.WriteByte(mask)
+	if s.Click {
+		binary.Write(w, binary.LittleEndian, math.Float64bits(s.ClickPos.X))
+		binary.Write(w, binary.LittleEndian, math.Float64bits(s.ClickPos.Y))
+	}
+}
+
+// Read decodes a recording written by Write.
+func Read(rd io.Reader) (*Recording, error) {
+	br := bufio.NewReader(rd)
+
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if string(head[:len(magic)]) != magic {
+		return nil, errors.New("not a replay file")
+	}
+	if v := head[len(magic)]; v != version {
+		return nil, fmt.Errorf("unsupported replay version %d", v)
+	}
+
+	r := &Recording{}
+	var err error
+	if r.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, fmt.Errorf("reading seed: %w", err)
+	}
+	rate, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading tick rate: %w", err)
+	}
+	if rate == 0 || rate > maxTickRate {
+		return nil, fmt.Errorf("bad tick rate %d", rate)
+	}
+	r.TickRate = int(rate)
+
+	for {
+		tag, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading record: %w", unexpected(err))
+		}
+		switch tag {
+		case tagEnd:
+			return r, nil
+
+		case tagStart:
+			start, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("reading start mode: %w", unexpected(err))
+			}
+			if start > math.MaxInt32 || !game.Mode(start).Valid() {
+				return nil, fmt.Errorf("unknown start mode %d", start)
+			}
+			r.Start = int(start)
+
+		case tagCheck:
+			tick, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("reading checksum: %w", unexpected(err))
+			}
+			var sum uint64
+			if err := binary.Read(br, binary.LittleEndian, &sum); err != nil {
+				return nil, fmt.Errorf("reading checksum: %w", unexpected(err))
+			}
+			r.Checks = append(r.Checks, Check{Tick: int(tick), Sum: sum})
+
+		case tagInput:
+			run, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("reading input run: %w", unexpected(err))
+			}
+			s, err := readState(br)
+			if err != nil {
+				return nil, fmt.Errorf("reading input run: %w", unexpected(err))
+			}
+			if run > uint64(maxSteps-len(r.Steps)) {
+				return nil, fmt.Errorf("more than %d steps", maxSteps)
+			}
+			for ; run > 0; run-- {
+				r.Steps = append(r.Steps, s)
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown record tag %q", tag)
+		}
+	}
+}
+
+func readState(r *bufio.Reader) (input.State, error) {
+	mask, err := r.ReadByte()
+	if err != nil {
+		return input.State{}, err
+	}
+	s := input.State{
+		Left:   mask&bitLeft != 0,
+		Right:  mask&bitRight != 0,
+		Up:     mask&bitUp != 0,
+		Down:   mask&bitDown != 0,
+		Back:   mask&bitBack != 0,
+		Pause:  mask&bitPause != 0,
+		Click:  mask&bitClick != 0,
+		Repeat: mask&bitRepeat != 0,
+	}
+	if s.Click {
+		var x, y uint64
+		if err := binary.Read(r, binary.LittleEndian, &x); err != nil {
+			return input.State{}, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &y); err != nil {
+			return input.State{}, err
+		}
+		s.ClickPos = pixel.V(math.Float64frombits(x), math.Float64frombits(y))
+	}
+	return s, nil
+}
+
+// unexpected turns a bare EOF in the middle of the stream into
+// io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+
+	"unicorn-toots/input"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		rec  *Recording
+	}{
+		{"empty", New(1, 60)},
+		{"negative seed", &Recording{Seed: -42, TickRate: 30}},
+		{"start mode", &Recording{Seed: 7, TickRate: 60, Start: 2}},
+		{"held keys", &Recording{
+			Seed:     7,
+			TickRate: 60,
+			Steps: []input.State{
+				{Right: true}, {Right: true}, {Right: true},
+				{Up: true, Left: true},
+				{}, {},
+				{Back: true}, {Pause: true}, {Down: true},
+			},
+		}},
+		{"clicks and repeats", &Recording{
+			Seed:     99,
+			TickRate: 60,
+			Steps: []input.State{
+				{Click: true, ClickPos: pixel.V(12.5, -3)},
+				{Click: true, ClickPos: pixel.V(12.5, -3)},
+				{Click: true, ClickPos: pixel.V(40, 80)},
+				{Repeat: true},
+			},
+		}},
+		{"checks", &Recording{
+			Seed:     3,
+			TickRate: 60,
+			Steps:    make([]input.State, 150),
+			Checks:   []Check{{60, 0xdeadbeef}, {120, 1}, {150, 1 << 63}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.rec.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.rec) {
+				t.Errorf("read back %+v, want %+v", got, tt.rec)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	valid := func() []byte {
+		var buf bytes.Buffer
+		rec := &Recording{Seed: 5, TickRate: 60, Steps: []input.State{{Left: true}}}
+		rec.AddCheck(77)
+		rec.Write(&buf)
+		return buf.Bytes()
+	}()
+
+	// head is a valid header: seed 1 at 60 steps a second.
+	head := magic + string(rune(version)) + "\x02\x3c"
+	// huge is a uvarint of 2^40.
+	huge := "\x80\x80\x80\x80\x80\x20"
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantEOF bool
+	}{
+		{"empty", nil, "reading header", false},
+		{"wrong magic", []byte("NOPE\x01"), "not a replay file", false},
+		{"version 0", []byte(magic + "\x00"), "unsupported replay version 0", false},
+		{"future version", []byte(magic + string(rune(version+1))), fmt.Sprintf("unsupported replay version %d", version+1), false},
+		{"no seed", []byte(magic + string(rune(version))), "reading seed", false},
+		{"no tick rate", []byte(magic + string(rune(version)) + "\x02"), "reading tick rate", false},
+		{"zero tick rate", []byte(magic + string(rune(version)) + "\x02\x00E"), "bad tick rate 0", false},
+		{"huge tick rate", []byte(magic + string(rune(version)) + "\x02" + huge + "E"), "bad tick rate", false},
+		{"unknown start mode", []byte(head + "S\x07E"), "unknown start mode 7", false},
+		{"huge start mode", []byte(head + "S" + huge + "E"), "unknown start mode", false},
+		{"huge input run", []byte(head + "I" + huge + "\x01E"), "more than", false},
+		{"runs adding up too far", []byte(head + strings.Repeat("I\x80\x80\x10\x01", 4) + "E"), "more than", false},
+		{"unknown tag", []byte(head + "X"), `unknown record tag 'X'`, false},
+		{"no end", valid[:len(valid)-1], "reading record", true},
+		{"cut off check", valid[:len(valid)-4], "reading checksum", true},
+		{"cut off click", []byte(head + "I\x01" + string(rune(bitClick)) + "\x00\x00"), "reading input run", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q, want it to mention %q", err, tt.want)
+			}
+			if tt.wantEOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("error %q is not io.ErrUnexpectedEOF", err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rec := &Recording{Checks: []Check{{60, 10}, {120, 20}}}
+	tests := []struct {
+		tick    int
+		sum     uint64
+		diverge bool
+	}{
+		{30, 99, false}, // no check recorded at this step
+		{60, 10, false},
+		{60, 11, true},
+		{120, 20, false},
+		{120, 10, true},
+	}
+	for _, tt := range tests {
+		err := rec.Verify(tt.tick, tt.sum)
+		var de *DivergenceError
+		if got := errors.As(err, &de); got != tt.diverge {
+			t.Errorf("Verify(%d, %d) = %v, want divergence %v", tt.tick, tt.sum, err, tt.diverge)
+			continue
+		}
+		if de != nil && (de.Tick != tt.tick || de.Got != tt.sum) {
+			t.Errorf("Verify(%d, %d) = %+v", tt.tick, tt.sum, de)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type jsonProperty struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID   uint32 `json:"firstgid"`
+	Source     string `json:"source"`
+	Name       string `json:"name"`
+	TileWidth  int    `json:"tilewidth"`
+	TileHeight int    `json:"tileheight"`
+	TileCount  int    `json:"tilecount"`
+	Columns    int    `json:"columns"`
+	Margin     int    `json:"margin"`
+	Spacing    int    `json:"spacing"`
+	Image      string `json:"image"`
+	ImageWidth int    `json:"imagewidth"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Visible     *bool           `json:"visible"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Properties  []jsonProperty  `json:"properties"`
+	Objects     []struct {
+		Name   string  `json:"name"`
+		Type   string  `json:"type"`
+		Class  string  `json:"class"`
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+		GID    uint32  `json:"gid"`
+	} `json:"objects"`
+}
+
+type jsonMap struct {
+	Orientation string        `json:"orientation"`
+	Infinite    bool          `json:"infinite"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Tilesets    []jsonTileset `json:"tilesets"`
+	Layers      []jsonLayer   `json:"layers"`
+}
+
+func parseJSON(name string, data []byte, open func(string) ([]byte, error)) (*Map, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return nil, err
+	}
+	switch {
+	case jm.Orientation != "orthogonal":
+		return nil, fmt.Errorf("%s maps aren't supported, only orthogonal ones", jm.Orientation)
+	case jm.Infinite:
+		return nil, errors.New("infinite maps aren't supported")
+	}
+
+	m := &Map{Width: jm.Width, Height: jm.Height, TileWidth: jm.TileWidth, TileHeight: jm.TileHeight}
+	dir := path.Dir(name)
+	for _, t := range jm.Tilesets {
+		if t.Source != "" {
+			ts, err := externalTileset(path.Join(dir, t.Source), open)
+			if err != nil {
+				return nil, err
+			}
+			ts.FirstGID = t.FirstGID
+			m.Tilesets = append(m.Tilesets, ts)
+			continue
+		}
+		m.Tilesets = append(m.Tilesets, t.tileset(dir))
+	}
+
+	for _, l := range jm.Layers {
+		switch l.Type {
+		case "tilelayer":
+			layer := &Layer{
+				Name:      l.Name,
+				Visible:   l.Visible == nil || *l.Visible,
+				Collision: strings.EqualFold(l.Name, "collision") || flag(l.Properties, "collision"),
+			}
+			tiles, err := jsonTiles(l)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", l.Name, err)
+			}
+			layer.Tiles = tiles
+			m.Layers = append(m.Layers, layer)
+		case "objectgroup":
+			for _, o := range l.Objects {
+				class := o.Class
+				if class == "" {
+					class = o.Type
+				}
+				m.Objects = append(m.Objects, m.object(o.Name, class, o.X, o.Y, o.Width, o.Height, o.GID != 0))
+			}
+		case "group":
+			return nil, errors.New("group layers aren't supported; move their layers out of the group")
+		}
+	}
+	return m, m.check()
+}
+
+// jsonTiles reads a tile layer's data, which is an array of IDs unless the
+// layer is saved base64-encoded.
+func jsonTiles(l jsonLayer) ([]uint32, error) {
+	if l.Encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(l.Data, &text); err != nil {
+			return nil, err
+		}
+		return decodeTiles(l.Encoding, l.Compression, text)
+	}
+	var tiles []uint32
+	err := json.Unmarshal(l.Data, &tiles)
+	return tiles, err
+}
+
+// flag reports whether the bool property called name is set.
+func flag(props []jsonProperty, name string) bool {
+	for _, p := range props {
+		if p.Name == name && p.Type == "bool" && p.Value == true {
+			return true
+		}
+	}
+	return false
+}
+
+// tileset converts a tileset whose image path is relative to dir.
+func (t jsonTileset) tileset(dir string) *Tileset {
+	ts := &Tileset{
+		Name:       t.Name,
+		FirstGID:   t.FirstGID,
+		TileWidth:  t.TileWidth,
+		TileHeight: t.TileHeight,
+		TileCount:  t.TileCount,
+		Columns:    t.Columns,
+		Margin:     t.Margin,
+		Spacing:    t.Spacing,
+	}
+	if t.Image != "" {
+		ts.Image = path.Join(dir, t.Image)
+	}
+	tilesetColumns(ts, t.ImageWidth)
+	return ts
+}
+
+// parseJSONTileset reads a tileset saved on its own as JSON.
+func parseJSONTileset(data []byte, dir string) (*Tileset, error) {
+	var t jsonTileset
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return t.tileset(dir), nil
+}
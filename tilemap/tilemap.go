@@ -0,0 +1,346 @@
+// Package tilemap reads orthogonal maps made in the Tiled editor, saved as
+// either TMX (XML) or JSON, and answers what a game needs of them: the
+// tiles to draw, the walls to bump into and the spawn points placed on
+// object layers.
+//
+// Map coordinates are in pixels with the origin at the map's bottom-left
+// corner and y pointing up, like the rest of the game; Tiled's own
+// top-down coordinates are converted on load.
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Bits Tiled sets in a tile's global ID to flip or rotate it.
+const (
+	flipH    = 0x80000000
+	flipV    = 0x40000000
+	flipD    = 0x20000000 // diagonal, used for rotation
+	hexRot   = 0x10000000
+	gidFlags = flipH | flipV | flipD | hexRot
+)
+
+// Map is a loaded tilemap.
+type Map struct {
+	Name string // file the map was read from
+
+	Width, Height         int // in tiles
+	TileWidth, TileHeight int // in pixels
+
+	Tilesets []*Tileset
+	Layers   []*Layer // tile layers, bottom first
+	Objects  []Object // every object on every object layer
+}
+
+// Tileset is an image cut into tiles. Picture is nil until the image has
+// been loaded; tiles from a tileset without one aren't drawn.
+type Tileset struct {
+	Name       string
+	FirstGID   uint32
+	Image      string // path of the image, relative to the map's filesystem
+	TileWidth  int
+	TileHeight int
+	TileCount  int
+	Columns    int
+	Margin     int
+	Spacing    int
+
+	Picture *pixel.PictureData
+}
+
+// Layer is a grid of tiles. Tiles holds a global tile ID for each cell,
+// row by row from the top as Tiled stores them, with 0 for no tile.
+type Layer struct {
+	Name    string
+	Visible bool
+
+	// Collision marks the layer's tiles as walls. A layer is a collision
+	// layer if it is called "collision" or has a bool property of that
+	// name set to true.
+	Collision bool
+
+	Tiles []uint32
+}
+
+// Object is a point or rectangle placed on an object layer.
+type Object struct {
+	Name  string
+	Class string     // Tiled's "class", called "type" before Tiled 1.9
+	Rect  pixel.Rect // in map coordinates; empty for a point
+}
+
+// Center returns the middle of the object, or the point itself.
+func (o Object) Center() pixel.Vec { return o.Rect.Center() }
+
+// Parse reads the map file called name, choosing TMX or JSON by its
+// extension. External tilesets are read with open, which is given paths
+// relative to the same filesystem as name.
+func Parse(name string, data []byte, open func(name string) ([]byte, error)) (*Map, error) {
+	var (
+		m   *Map
+		err error
+	)
+	switch strings.ToLower(path.Ext(name)) {
+	case ".tmx":
+		m, err = parseTMX(name, data, open)
+	case ".tmj", ".json":
+		m, err = parseJSON(name, data, open)
+	default:
+		return nil, fmt.Errorf("%s: not a .tmx, .tmj or .json map", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	m.Name = name
+	return m, nil
+}
+
+// Size returns the map's size in pixels.
+func (m *Map) Size() pixel.Vec {
+	return pixel.V(float64(m.Width*m.TileWidth), float64(m.Height*m.TileHeight))
+}
+
+// Bounds returns the area the map covers, in map coordinates.
+func (m *Map) Bounds() pixel.Rect { return pixel.Rect{Max: m.Size()} }
+
+// cell returns the area of the cell at col, row, counting rows from the top.
+func (m *Map) cell(col, row int) pixel.Rect {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	x, y := float64(col)*tw, float64(m.Height-1-row)*th
+	return pixel.R(x, y, x+tw, y+th)
+}
+
+// Walls returns the solid areas of the collision layers, with neighbouring
+// tiles along a row joined into one rectangle.
+func (m *Map) Walls() []pixel.Rect {
+	var walls []pixel.Rect
+	for row := 0; row < m.Height; row++ {
+		start := -1
+		for col := 0; col <= m.Width; col++ {
+			if col < m.Width && m.solid(col, row) {
+				if start < 0 {
+					start = col
+				}
+				continue
+			}
+			if start >= 0 {
+				walls = append(walls, m.cell(start, row).Union(m.cell(col-1, row)))
+				start = -1
+			}
+		}
+	}
+	return walls
+}
+
+func (m *Map) solid(col, row int) bool {
+	for _, l := range m.Layers {
+		if l.Collision && l.Tiles[row*m.Width+col]&^gidFlags != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Spawns returns the centres of the objects of the given class, in the
+// order they were placed.
+func (m *Map) Spawns(class string) []pixel.Vec {
+	var at []pixel.Vec
+	for _, o := range m.Objects {
+		if strings.EqualFold(o.Class, class) {
+			at = append(at, o.Center())
+		}
+	}
+	return at
+}
+
+// Draw draws the visible layers onto t, with the map's bottom-left corner
+// at the origin of mat. Collision layers are drawn too if they are visible,
+// so a map can use its walls as scenery.
+func (m *Map) Draw(t pixel.Target, mat pixel.Matrix) {
+	spr := pixel.NewSprite(nil, pixel.Rect{})
+	for _, l := range m.Layers {
+		if !l.Visible {
+			continue
+		}
+		for i, gid := range l.Tiles {
+			ts, frame := m.tile(gid)
+			if ts == nil {
+				continue
+			}
+			// Tiles larger than the grid hang up from the cell's
+			// bottom-left corner, as Tiled draws them.
+			c := m.cell(i%m.Width, i/m.Width)
+			at := c.Min.Add(frame.Size().Scaled(0.5))
+			tm := pixel.IM
+			if gid&flipH != 0 {
+				tm = tm.ScaledXY(pixel.ZV, pixel.V(-1, 1))
+			}
+			if gid&flipV != 0 {
+				tm = tm.ScaledXY(pixel.ZV, pixel.V(1, -1))
+			}
+			spr.Set(ts.Picture, frame)
+			spr.Draw(t, tm.Moved(at).Chained(mat))
+		}
+	}
+}
+
+// tile returns the tileset holding gid and the tile's area of its picture.
+// It returns nil for empty cells and tiles whose image isn't loaded.
+func (m *Map) tile(gid uint32) (*Tileset, pixel.Rect) {
+	id := gid &^ gidFlags
+	if id == 0 {
+		return nil, pixel.Rect{}
+	}
+	var ts *Tileset
+	for _, s := range m.Tilesets {
+		if s.FirstGID <= id && (ts == nil || s.FirstGID > ts.FirstGID) {
+			ts = s
+		}
+	}
+	if ts == nil || ts.Picture == nil {
+		return nil, pixel.Rect{}
+	}
+	local := int(id - ts.FirstGID)
+	col, row := local%ts.Columns, local/ts.Columns
+	x := ts.Margin + col*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + row*(ts.TileHeight+ts.Spacing)
+	b := ts.Picture.Bounds()
+	return ts, pixel.R(
+		b.Min.X+float64(x), b.Max.Y-float64(y+ts.TileHeight),
+		b.Min.X+float64(x+ts.TileWidth), b.Max.Y-float64(y),
+	)
+}
+
+// check validates a freshly parsed map, reporting every problem at once.
+func (m *Map) check() error {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if m.Width <= 0 || m.Height <= 0 || m.TileWidth <= 0 || m.TileHeight <= 0 {
+		fail("map is %dx%d tiles of %dx%d pixels", m.Width, m.Height, m.TileWidth, m.TileHeight)
+	}
+	for _, ts := range m.Tilesets {
+		if ts.TileWidth <= 0 || ts.TileHeight <= 0 || ts.Columns <= 0 {
+			fail("tileset %q has no usable tile size or columns", ts.Name)
+		}
+	}
+	for _, l := range m.Layers {
+		if len(l.Tiles) != m.Width*m.Height {
+			fail("layer %q has %d tiles, not %d", l.Name, len(l.Tiles), m.Width*m.Height)
+			continue
+		}
+		for _, gid := range l.Tiles {
+			if gid&(flipD|hexRot) != 0 {
+				fail("layer %q has rotated tiles, which aren't supported", l.Name)
+				break
+			}
+			if id := gid &^ gidFlags; id != 0 && !m.known(id) {
+				fail("layer %q uses tile %d, which no tileset has", l.Name, id)
+				break
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// known reports whether some tileset covers the global tile ID id.
+func (m *Map) known(id uint32) bool {
+	for _, ts := range m.Tilesets {
+		if id >= ts.FirstGID && (ts.TileCount == 0 || id < ts.FirstGID+uint32(ts.TileCount)) {
+			return true
+		}
+	}
+	return false
+}
+
+// object converts an object from Tiled's coordinates, measured down from
+// the map's top-left corner. Tile objects are anchored at their bottom-left
+// corner rather than their top-left.
+func (m *Map) object(name, class string, x, y, w, h float64, tile bool) Object {
+	top := m.Size().Y - y
+	if tile {
+		top += h
+	}
+	return Object{Name: name, Class: class, Rect: pixel.R(x, top-h, x+w, top)}
+}
+
+// tilesetColumns works out how many tiles fit across a tileset image, for
+// files old enough not to say.
+func tilesetColumns(ts *Tileset, imageWidth int) {
+	if ts.Columns == 0 && ts.TileWidth > 0 {
+		ts.Columns = (imageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+}
+
+// decodeTiles reads a layer's tile IDs stored as text: comma-separated, or
+// base64 little-endian words, optionally zlib or gzip compressed.
+func decodeTiles(encoding, compression, text string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var tiles []uint32
+		for _, f := range strings.Split(text, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			n, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("bad tile %q", f)
+			}
+			tiles = append(tiles, uint32(n))
+		}
+		return tiles, nil
+	case "base64":
+	default:
+		return nil, fmt.Errorf("unknown tile encoding %q", encoding)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s compression isn't supported; save the map with zlib, gzip or none", compression)
+	}
+	if raw, err = io.ReadAll(r); err != nil {
+		return nil, err
+	}
+	if len(raw)%4 != 0 {
+		return nil, errors.New("tile data isn't a whole number of tiles")
+	}
+	tiles := make([]uint32, len(raw)/4)
+	for i := range tiles {
+		tiles[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return tiles, nil
+}
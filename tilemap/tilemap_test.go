@@ -0,0 +1,258 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// tiles is the layer every encoding below stores: a 4x3 map with a wall
+// along the top row, one along the left edge and a flipped tile.
+var tiles = []uint32{
+	2, 2, 2, 2,
+	2, 0, flipH | 1, 0,
+	2, 1, 0, 0,
+}
+
+func csvTiles() string {
+	var s []string
+	for _, t := range tiles {
+		s = append(s, fmt.Sprint(t))
+	}
+	return strings.Join(s, ",")
+}
+
+func base64Tiles(compression string) string {
+	raw := make([]byte, 4*len(tiles))
+	for i, t := range tiles {
+		binary.LittleEndian.PutUint32(raw[i*4:], t)
+	}
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	default:
+		buf.Write(raw)
+	}
+	if w != nil {
+		w.Write(raw)
+		w.Close()
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// tmx returns a TMX map holding tiles with its data element given.
+func tmx(data string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="4" height="3" tilewidth="8" tileheight="8" infinite="0">
+ <tileset firstgid="1" name="tiles" tilewidth="8" tileheight="8" tilecount="2" columns="2">
+  <image source="tiles.png" width="16" height="8"/>
+ </tileset>
+ <layer id="1" name="collision" width="4" height="3">
+  ` + data + `
+ </layer>
+ <objectgroup id="2" name="spawns">
+  <object id="1" name="start" type="unicorn" x="4" y="4"/>
+  <object id="2" class="letter" x="8" y="8" width="16" height="8"/>
+  <object id="3" class="gem" gid="1" x="16" y="24" width="8" height="8"/>
+ </objectgroup>
+</map>`
+}
+
+// tmj returns a JSON map holding tiles with the layer fields given.
+func tmj(layer string) string {
+	return `{
+ "orientation": "orthogonal", "width": 4, "height": 3, "tilewidth": 8, "tileheight": 8, "infinite": false,
+ "tilesets": [{"firstgid": 1, "name": "tiles", "tilewidth": 8, "tileheight": 8, "tilecount": 2, "columns": 2, "image": "tiles.png", "imagewidth": 16}],
+ "layers": [
+  {"type": "tilelayer", "name": "collision", ` + layer + `},
+  {"type": "objectgroup", "name": "spawns", "objects": [
+   {"name": "start", "type": "unicorn", "x": 4, "y": 4},
+   {"class": "letter", "x": 8, "y": 8, "width": 16, "height": 8},
+   {"class": "gem", "gid": 1, "x": 16, "y": 24, "width": 8, "height": 8}
+  ]}
+ ]
+}`
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, file, data string
+	}{
+		{"tmx xml", "maps/a.tmx", tmx(`<data>` + xmlTiles() + `</data>`)},
+		{"tmx csv", "maps/a.tmx", tmx(`<data encoding="csv">` + csvTiles() + `</data>`)},
+		{"tmx base64", "maps/a.tmx", tmx(`<data encoding="base64">` + base64Tiles("") + `</data>`)},
+		{"tmx zlib", "maps/a.tmx", tmx(`<data encoding="base64" compression="zlib">` + base64Tiles("zlib") + `</data>`)},
+		{"tmx gzip", "maps/a.tmx", tmx(`<data encoding="base64" compression="gzip">` + base64Tiles("gzip") + `</data>`)},
+		{"json array", "maps/a.tmj", tmj(`"data": [` + csvTiles() + `]`)},
+		{"json base64", "maps/a.json", tmj(`"encoding": "base64", "data": "` + base64Tiles("") + `"`)},
+		{"json zlib", "maps/a.tmj", tmj(`"encoding": "base64", "compression": "zlib", "data": "` + base64Tiles("zlib") + `"`)},
+		{"json gzip", "maps/a.tmj", tmj(`"encoding": "base64", "compression": "gzip", "data": "` + base64Tiles("gzip") + `"`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.file, []byte(tt.data), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Name != tt.file || m.Size() != pixel.V(32, 24) {
+				t.Errorf("map %q is %v", m.Name, m.Size())
+			}
+			if len(m.Layers) != 1 || !m.Layers[0].Collision || !m.Layers[0].Visible {
+				t.Fatalf("layers %+v", m.Layers)
+			}
+			if got := m.Layers[0].Tiles; !reflect.DeepEqual(got, tiles) {
+				t.Errorf("tiles %v, want %v", got, tiles)
+			}
+			if len(m.Tilesets) != 1 || m.Tilesets[0].Image != "maps/tiles.png" {
+				t.Errorf("tilesets %+v", m.Tilesets)
+			}
+
+			// Rows join into walls, counted up from the bottom.
+			wantWalls := []pixel.Rect{
+				pixel.R(0, 16, 32, 24),
+				pixel.R(0, 8, 8, 16), pixel.R(16, 8, 24, 16),
+				pixel.R(0, 0, 16, 8),
+			}
+			if got := m.Walls(); !reflect.DeepEqual(got, wantWalls) {
+				t.Errorf("walls %v, want %v", got, wantWalls)
+			}
+
+			wantObjects := []Object{
+				{Name: "start", Class: "unicorn", Rect: pixel.R(4, 20, 4, 20)},
+				{Class: "letter", Rect: pixel.R(8, 8, 24, 16)},
+				// Tile objects hang from their bottom-left corner.
+				{Class: "gem", Rect: pixel.R(16, 0, 24, 8)},
+			}
+			if !reflect.DeepEqual(m.Objects, wantObjects) {
+				t.Errorf("objects %+v, want %+v", m.Objects, wantObjects)
+			}
+			if got := m.Spawns("Unicorn"); !reflect.DeepEqual(got, []pixel.Vec{pixel.V(4, 20)}) {
+				t.Errorf("unicorn spawns %v", got)
+			}
+		})
+	}
+}
+
+func xmlTiles() string {
+	var s strings.Builder
+	for _, t := range tiles {
+		fmt.Fprintf(&s, `<tile gid="%d"/>`, t)
+	}
+	return s.String()
+}
+
+func TestTile(t *testing.T) {
+	m, err := Parse("a.tmx", []byte(tmx(`<data encoding="csv">`+csvTiles()+`</data>`)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Tilesets[0].Picture = pixel.MakePictureData(pixel.R(0, 0, 16, 8))
+	tests := []struct {
+		gid  uint32
+		want pixel.Rect
+	}{
+		{1, pixel.R(0, 0, 8, 8)},
+		{2, pixel.R(8, 0, 16, 8)},
+		{flipH | 2, pixel.R(8, 0, 16, 8)},
+	}
+	for _, tt := range tests {
+		ts, r := m.tile(tt.gid)
+		if ts == nil || r != tt.want {
+			t.Errorf("tile(%#x) = %v, want %v", tt.gid, r, tt.want)
+		}
+	}
+	if ts, _ := m.tile(0); ts != nil {
+		t.Error("empty cell has a tile")
+	}
+}
+
+func TestExternalTilesets(t *testing.T) {
+	files := map[string]string{
+		"maps/sets/tiles.tsx": `<tileset name="tiles" tilewidth="8" tileheight="8" tilecount="2" spacing="2" margin="1">
+ <image source="tiles.png" width="20" height="10"/>
+</tileset>`,
+		"maps/sets/tiles.tsj": `{"name": "tiles", "tilewidth": 8, "tileheight": 8, "tilecount": 2, "columns": 2, "image": "../art/tiles.png"}`,
+	}
+	open := func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("no file %s", name)
+		}
+		return []byte(data), nil
+	}
+
+	tests := []struct {
+		name, file, data string
+		image            string
+		columns          int
+	}{
+		{"tsx", "maps/a.tmx", strings.Replace(tmx(`<data encoding="csv">`+csvTiles()+`</data>`),
+			`<tileset firstgid="1" name="tiles"`, `<tileset firstgid="1" source="sets/tiles.tsx"/><tileset firstgid="99" name="unused"`, 1),
+			"maps/sets/tiles.png", 2},
+		{"tsj", "maps/a.tmj", strings.Replace(tmj(`"data": [`+csvTiles()+`]`),
+			`{"firstgid": 1, "name": "tiles"`, `{"firstgid": 1, "source": "sets/tiles.tsj"}, {"firstgid": 99, "name": "unused"`, 1),
+			"maps/art/tiles.png", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.file, []byte(tt.data), open)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := m.Tilesets[0]
+			if ts.FirstGID != 1 || ts.Image != tt.image || ts.Columns != tt.columns {
+				t.Errorf("tileset %+v", ts)
+			}
+		})
+	}
+
+	_, err := Parse("maps/a.tmj", []byte(tests[1].data), nil)
+	if err == nil || !strings.Contains(err.Error(), "no way to read external tilesets") {
+		t.Errorf("without open: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	csv := `<data encoding="csv">` + csvTiles() + `</data>`
+	tests := []struct {
+		name, file, data, want string
+	}{
+		{"extension", "a.txt", "", "not a .tmx, .tmj or .json map"},
+		{"isometric", "a.tmx", strings.Replace(tmx(csv), "orthogonal", "isometric", 1), "isometric maps aren't supported"},
+		{"infinite", "a.tmj", strings.Replace(tmj(`"data": []`), `"infinite": false`, `"infinite": true`, 1), "infinite maps"},
+		{"group", "a.tmx", strings.Replace(tmx(csv), "</map>", "<group/></map>", 1), "group layers"},
+		{"short layer", "a.tmx", tmx(`<data encoding="csv">1,2,0</data>`), `layer "collision" has 3 tiles, not 12`},
+		{"unknown tile", "a.tmj", tmj(`"data": [` + strings.Replace(csvTiles(), "2", "7", 1) + `]`), "uses tile 7, which no tileset has"},
+		{"rotated", "a.tmx", tmx(`<data encoding="csv">` + strings.Replace(csvTiles(), "0", fmt.Sprint(flipD|1), 1) + `</data>`), "rotated tiles"},
+		{"bad csv", "a.tmx", tmx(`<data encoding="csv">1,x,2</data>`), `bad tile "x"`},
+		{"encoding", "a.tmx", tmx(`<data encoding="hex">00</data>`), `unknown tile encoding "hex"`},
+		{"zstd", "a.tmx", tmx(`<data encoding="base64" compression="zstd">` + base64Tiles("") + `</data>`), "zstd compression isn't supported"},
+		{"bad base64", "a.tmx", tmx(`<data encoding="base64">!!!</data>`), "illegal base64"},
+		{"ragged", "a.tmx", tmx(`<data encoding="base64">AAAA</data>`), "whole number of tiles"},
+		{"bad zlib", "a.tmx", tmx(`<data encoding="base64" compression="zlib">` + base64Tiles("") + `</data>`), "zlib"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.file, []byte(tt.data), nil)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type tmxProperties struct {
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Type  string `xml:"type,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"properties>property"`
+}
+
+// flag reports whether the bool property called name is set.
+func (p tmxProperties) flag(name string) bool {
+	for _, prop := range p.Properties {
+		if prop.Name == name && prop.Type == "bool" && prop.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+type tmxTileset struct {
+	FirstGID   uint32 `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	Margin     int    `xml:"margin,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+		Width  int    `xml:"width,attr"`
+	} `xml:"image"`
+}
+
+type tmxLayer struct {
+	tmxProperties
+	Name    string `xml:"name,attr"`
+	Visible string `xml:"visible,attr"` // "0" when hidden, absent otherwise
+	Data    struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Text        string `xml:",chardata"`
+		Tiles       []struct {
+			GID uint32 `xml:"gid,attr"`
+		} `xml:"tile"`
+	} `xml:"data"`
+}
+
+type tmxObject struct {
+	Name   string  `xml:"name,attr"`
+	Type   string  `xml:"type,attr"`
+	Class  string  `xml:"class,attr"`
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+	GID    uint32  `xml:"gid,attr"`
+}
+
+type tmxMap struct {
+	Orientation string       `xml:"orientation,attr"`
+	Infinite    bool         `xml:"infinite,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Tilesets    []tmxTileset `xml:"tileset"`
+	Layers      []tmxLayer   `xml:"layer"`
+	Groups      []struct {
+		Objects []tmxObject `xml:"object"`
+	} `xml:"objectgroup"`
+	GroupLayers []struct{} `xml:"group"`
+}
+
+func parseTMX(name string, data []byte, open func(string) ([]byte, error)) (*Map, error) {
+	var tm tmxMap
+	if err := xml.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+	switch {
+	case tm.Orientation != "orthogonal":
+		return nil, fmt.Errorf("%s maps aren't supported, only orthogonal ones", tm.Orientation)
+	case tm.Infinite:
+		return nil, errors.New("infinite maps aren't supported")
+	case len(tm.GroupLayers) > 0:
+		return nil, errors.New("group layers aren't supported; move their layers out of the group")
+	}
+
+	m := &Map{Width: tm.Width, Height: tm.Height, TileWidth: tm.TileWidth, TileHeight: tm.TileHeight}
+	dir := path.Dir(name)
+	for _, t := range tm.Tilesets {
+		if t.Source != "" {
+			ts, err := externalTileset(path.Join(dir, t.Source), open)
+			if err != nil {
+				return nil, err
+			}
+			ts.FirstGID = t.FirstGID
+			m.Tilesets = append(m.Tilesets, ts)
+			continue
+		}
+		m.Tilesets = append(m.Tilesets, t.tileset(dir))
+	}
+
+	for _, l := range tm.Layers {
+		layer := &Layer{
+			Name:      l.Name,
+			Visible:   l.Visible != "0",
+			Collision: strings.EqualFold(l.Name, "collision") || l.flag("collision"),
+		}
+		if l.Data.Encoding == "" {
+			for _, t := range l.Data.Tiles {
+				layer.Tiles = append(layer.Tiles, t.GID)
+			}
+		} else {
+			tiles, err := decodeTiles(l.Data.Encoding, l.Data.Compression, l.Data.Text)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", l.Name, err)
+			}
+			layer.Tiles = tiles
+		}
+		m.Layers = append(m.Layers, layer)
+	}
+
+	for _, g := range tm.Groups {
+		for _, o := range g.Objects {
+			class := o.Class
+			if class == "" {
+				class = o.Type
+			}
+			m.Objects = append(m.Objects, m.object(o.Name, class, o.X, o.Y, o.Width, o.Height, o.GID != 0))
+		}
+	}
+	return m, m.check()
+}
+
+// tileset converts a tileset whose image path is relative to dir.
+func (t tmxTileset) tileset(dir string) *Tileset {
+	ts := &Tileset{
+		Name:       t.Name,
+		FirstGID:   t.FirstGID,
+		TileWidth:  t.TileWidth,
+		TileHeight: t.TileHeight,
+		TileCount:  t.TileCount,
+		Columns:    t.Columns,
+		Margin:     t.Margin,
+		Spacing:    t.Spacing,
+	}
+	if t.Image.Source != "" {
+		ts.Image = path.Join(dir, t.Image.Source)
+	}
+	tilesetColumns(ts, t.Image.Width)
+	return ts
+}
+
+// externalTileset reads a tileset saved in its own file, as TSX or JSON.
+func externalTileset(name string, open func(string) ([]byte, error)) (*Tileset, error) {
+	if open == nil {
+		return nil, fmt.Errorf("tileset %s: no way to read external tilesets", name)
+	}
+	data, err := open(name)
+	if err != nil {
+		return nil, fmt.Errorf("tileset %s: %w", name, err)
+	}
+	if strings.HasSuffix(strings.ToLower(name), ".tsx") {
+		var t tmxTileset
+		if err := xml.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("tileset %s: %w", name, err)
+		}
+		return t.tileset(path.Dir(name)), nil
+	}
+	ts, err := parseJSONTileset(data, path.Dir(name))
+	if err != nil {
+		return nil, fmt.Errorf("tileset %s: %w", name, err)
+	}
+	return ts, nil
+}
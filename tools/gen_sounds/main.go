@@ -0,0 +1,111 @@
+// Command gen_sounds synthesizes the game's default sound effects into
+// assets/sounds as 16-bit mono WAV files. Run it from the repository root
+// after changing a sound here.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const rate = 22050
+
+// note is a tone starting at a time into the sound.
+type note struct {
+	at, length float64 // seconds
+	from, to   float64 // pitch in Hz, sliding from one to the other
+	square     bool    // a reedy square wave rather than a pure sine
+}
+
+var sounds = map[string][]note{
+	// A quick rising pair of chimes.
+	"letter": {
+		{at: 0, length: 0.12, from: 1047, to: 1047},
+		{at: 0.08, length: 0.2, from: 1319, to: 1319},
+	},
+	// A deflating toot.
+	"wrong": {
+		{at: 0, length: 0.45, from: 320, to: 150, square: true},
+	},
+	// A happy arpeggio up to the octave.
+	"word": {
+		{at: 0, length: 0.2, from: 523, to: 523},
+		{at: 0.12, length: 0.2, from: 659, to: 659},
+		{at: 0.24, length: 0.2, from: 784, to: 784},
+		{at: 0.36, length: 0.4, from: 1047, to: 1047},
+	},
+	// A bright ping with a shimmer on top.
+	"gem": {
+		{at: 0, length: 0.3, from: 1760, to: 1760},
+		{at: 0.04, length: 0.25, from: 2637, to: 2637},
+	},
+}
+
+func main() {
+	dir := filepath.Join("assets", "sounds")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fail(err)
+	}
+	for name, notes := range sounds {
+		if err := os.WriteFile(filepath.Join(dir, name+".wav"), wav(render(notes)), 0o644); err != nil {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "gen_sounds:", err)
+	os.Exit(1)
+}
+
+// render mixes notes into samples from -1 to 1.
+func render(notes []note) []float64 {
+	end := 0.0
+	for _, n := range notes {
+		end = math.Max(end, n.at+n.length)
+	}
+	out := make([]float64, int(end*rate))
+	for _, n := range notes {
+		phase := 0.0
+		start := int(n.at * rate)
+		count := int(n.length * rate)
+		for i := 0; i < count && start+i < len(out); i++ {
+			t := float64(i) / float64(count)
+			phase += 2 * math.Pi * (n.from + (n.to-n.from)*t) / rate
+			v := math.Sin(phase)
+			if n.square {
+				v = math.Copysign(0.6, v)
+			}
+			// A click-free attack, then an exponential fade.
+			env := math.Min(1, float64(i)/(0.005*rate)) * math.Exp(-4*t)
+			out[start+i] += 0.4 * v * env
+		}
+	}
+	return out
+}
+
+// wav encodes samples as a 16-bit mono WAV file.
+func wav(samples []float64) []byte {
+	var data bytes.Buffer
+	for _, s := range samples {
+		binary.Write(&data, binary.LittleEndian, int16(math.Max(-1, math.Min(s, 1))*math.MaxInt16))
+	}
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+data.Len()))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []any{
+		uint32(16), uint16(1), uint16(1), // PCM, mono
+		uint32(rate), uint32(rate * 2), uint16(2), uint16(16),
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(data.Len()))
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
@@ -0,0 +1,175 @@
+// Command gen_unicorn_sheet builds the unicorn's animated spritesheet and
+// its sidecar metadata from the single unicorn-v2.png frame kept beside it,
+// which the game itself never loads. Run it from the repository root after
+// editing that frame.
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+const size = 32
+
+var (
+	yellow = color.RGBA{255, 255, 0, 255}
+	white  = color.RGBA{255, 255, 255, 255}
+)
+
+// Parts of the unicorn-v2.png frame, which faces left.
+var (
+	whole      = image.Rect(0, 0, size, 26)
+	tail       = image.Rect(22, 4, size, 11)
+	frontLegs  = image.Rect(0, 19, 13, 26)
+	backLegs   = image.Rect(13, 19, size, 26)
+	sparkleAts = []image.Point{{3, 3}, {28, 5}, {27, 27}, {4, 28}}
+)
+
+// moved returns a copy of img with the pixels inside r shifted by d. What
+// they leave behind is cleared.
+func moved(img *image.RGBA, r image.Rectangle, d image.Point) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, image.Point{}, draw.Src)
+	draw.Draw(out, r, image.Transparent, image.Point{}, draw.Src)
+	draw.Draw(out, r.Add(d), img, r.Min, draw.Over)
+	return out
+}
+
+// sparkle draws small yellow and white crosses in the empty corners.
+func sparkle(img *image.RGBA, phase int) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, image.Point{}, draw.Src)
+	for i, p := range sparkleAts {
+		if (i+phase)%2 == 1 {
+			continue
+		}
+		out.SetRGBA(p.X, p.Y, white)
+		for _, d := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			out.SetRGBA(p.X+d.X, p.Y+d.Y, yellow)
+		}
+	}
+	return out
+}
+
+// gloomy tints img blue-grey.
+func gloomy(img *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	for i := 0; i < len(img.Pix); i += 4 {
+		r, g, b := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+		grey := (r + g + b) / 3
+		out.Pix[i] = uint8(grey * 8 / 10)
+		out.Pix[i+1] = uint8(grey * 8 / 10)
+		out.Pix[i+2] = uint8(min(grey+20, 255))
+		out.Pix[i+3] = img.Pix[i+3]
+	}
+	return out
+}
+
+type frame struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type animation struct {
+	Name      string    `json:"name"`
+	Frames    []int     `json:"frames"`
+	Duration  float64   `json:"duration,omitempty"`
+	Durations []float64 `json:"durations,omitempty"`
+	Loop      bool      `json:"loop"`
+}
+
+type clip struct {
+	animation
+	images []*image.RGBA
+}
+
+func main() {
+	f, err := os.Open("tools/gen_unicorn_sheet/unicorn-v2.png")
+	if err != nil {
+		panic(err)
+	}
+	src, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		panic(err)
+	}
+	base := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(base, base.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	up := image.Pt(0, -1)
+	stride := func(front, back int) *image.RGBA {
+		return moved(moved(base, frontLegs, image.Pt(front, 0)), backLegs, image.Pt(back, 0))
+	}
+
+	clips := []clip{
+		{animation{Name: "idle", Durations: []float64{1.2, 0.3}, Loop: true}, []*image.RGBA{
+			base,
+			moved(base, tail, up),
+		}},
+		{animation{Name: "walk", Loop: true}, []*image.RGBA{
+			base,
+			moved(stride(-1, 1), whole, up),
+			base,
+			moved(stride(1, -1), whole, up),
+		}},
+		{animation{Name: "celebrate", Duration: 0.12, Loop: true}, []*image.RGBA{
+			moved(base, whole, image.Pt(0, 1)),
+			sparkle(moved(base, whole, image.Pt(0, -2)), 0),
+			sparkle(moved(base, whole, image.Pt(0, -2)), 1),
+			base,
+		}},
+		{animation{Name: "happy", Durations: []float64{0.08, 0.08, 0.16, 0.08}}, []*image.RGBA{
+			moved(base, whole, up),
+			sparkle(moved(base, whole, image.Pt(0, -2)), 0),
+			moved(base, whole, image.Pt(0, -2)),
+			moved(base, whole, up),
+		}},
+		{animation{Name: "sad", Duration: 0.6, Loop: true}, []*image.RGBA{
+			gloomy(moved(base, whole, image.Pt(0, 1))),
+			gloomy(moved(moved(base, whole, image.Pt(0, 1)), tail, image.Pt(0, 2))),
+		}},
+	}
+
+	// One row per clip.
+	cols := 0
+	for _, c := range clips {
+		cols = max(cols, len(c.images))
+	}
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*size, len(clips)*size))
+	var meta struct {
+		Frames     []frame     `json:"frames"`
+		Animations []animation `json:"animations"`
+	}
+	for row, c := range clips {
+		for col, img := range c.images {
+			r := image.Rect(col*size, row*size, (col+1)*size, (row+1)*size)
+			draw.Draw(sheet, r, img, image.Point{}, draw.Src)
+			c.Frames = append(c.Frames, len(meta.Frames))
+			meta.Frames = append(meta.Frames, frame{r.Min.X, r.Min.Y, size, size})
+		}
+		meta.Animations = append(meta.Animations, c.animation)
+	}
+
+	out, err := os.Create("assets/unicorn-sheet.png")
+	if err != nil {
+		panic(err)
+	}
+	if err := png.Encode(out, sheet); err != nil {
+		panic(err)
+	}
+	out.Close()
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile("assets/unicorn-sheet.json", append(data, '\n'), 0o644); err != nil {
+		panic(err)
+	}
+}